@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/middleware"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 100
+)
+
+type HTTPHandler struct {
+	Logger   *logrus.Logger
+	Validate *validator.Validate
+	Usecase  Usecase
+}
+
+func NewAuditHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, basicAuth middleware.RouteMiddleware, usecase Usecase) {
+	handler := &HTTPHandler{
+		Logger:   logger,
+		Validate: validate,
+		Usecase:  usecase,
+	}
+
+	router.HandleFunc("/go-oauth/v1/audit", basicAuth.Verify(handler.GetEvents)).Methods(http.MethodGet)
+}
+
+func (handler *HTTPHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	payload := model.AuditQuery{
+		Actor:    query.Get("actor"),
+		ClientId: query.Get("clientId"),
+		Type:     query.Get("type"),
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Page:     intParam(query.Get("page"), defaultPage),
+		Limit:    intParam(query.Get("limit"), defaultLimit),
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidParameter, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp := handler.Usecase.GetEvents(ctx, payload)
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) validateRequestBody(body interface{}) (err error) {
+	err = handler.Validate.Struct(body)
+	if err == nil {
+		return
+	}
+
+	errorFields := err.(validator.ValidationErrors)
+	errorField := errorFields[0]
+	err = fmt.Errorf("invalid '%s' with value '%v'", errorField.Field(), errorField.Value())
+
+	return
+}
+
+// intParam value of a numeric query parameter, the fallback when it is absent
+func intParam(value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		// out of range on purpose, validation reports the parameter
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type UsecaseAuditProperty struct {
+	ServiceName     string
+	Logger          *logrus.Logger
+	Location        *time.Location
+	AuditRepository AuditRepository
+	// Retention how long events are kept, zero keeps them forever
+	Retention time.Duration
+}
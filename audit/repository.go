@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepository interface {
+	InsertOne(ctx context.Context, entryData entity.AuditEvent) (err error)
+	// Find newest events first
+	Find(ctx context.Context, filter bson.M, skip, limit int64) (events []entity.AuditEvent, err error)
+	CountDocuments(ctx context.Context, filter bson.M) (count int64, err error)
+	// EnsureIndexes create the index removing the events once they are older
+	// than the retention, a zero retention keeps them forever
+	EnsureIndexes(ctx context.Context, retention time.Duration) (err error)
+}
+
+type auditRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewAuditRepository(logger *logrus.Logger, db mongodb.Database) AuditRepository {
+	col := db.Collection("audit_log")
+	return &auditRepository{logger, col}
+}
+
+func (r *auditRepository) InsertOne(ctx context.Context, entryData entity.AuditEvent) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *auditRepository) Find(ctx context.Context, filter bson.M, skip, limit int64) (events []entity.AuditEvent, err error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetSkip(skip).SetLimit(limit)
+
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+	defer cursor.Close(ctx)
+
+	events = []entity.AuditEvent{}
+	for cursor.Next(ctx) {
+		var event entity.AuditEvent
+		if err = cursor.Decode(&event); err != nil {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		events = append(events, event)
+	}
+
+	return
+}
+
+func (r *auditRepository) CountDocuments(ctx context.Context, filter bson.M) (count int64, err error) {
+	if count, err = r.col.CountDocuments(ctx, filter); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *auditRepository) EnsureIndexes(ctx context.Context, retention time.Duration) (err error) {
+	index := mongodb.TTLIndex("created_at", retention)
+	if retention > 0 {
+		err = mongodb.EnsureIndexes(ctx, r.col, index)
+	} else {
+		err = mongodb.DropIndexIfExists(ctx, r.col, *index.Options.Name)
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	getAuditEventsSuccessMessage = "Get Audit Events Successfully"
+)
+
+type Usecase interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+	GetEvents(ctx context.Context, query model.AuditQuery) response.Response
+	// EnsureIndexes apply the retention, mongodb removes the events past it
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type usecase struct {
+	serviceName     string
+	logger          *logrus.Logger
+	auditRepository AuditRepository
+	loc             *time.Location
+	retention       time.Duration
+}
+
+func NewAuditUsecase(property UsecaseAuditProperty) *usecase {
+	return &usecase{
+		serviceName:     property.ServiceName,
+		logger:          property.Logger,
+		auditRepository: property.AuditRepository,
+		loc:             property.Location,
+		retention:       property.Retention,
+	}
+}
+
+// Record write the event, completed with what the request context knows.
+// Failing to write it is logged, it never fails the audited operation.
+func (u *usecase) Record(ctx context.Context, event entity.AuditEvent) {
+	ctx, span := tracing.Start(ctx, "audit.Record")
+	defer span.End()
+
+	event.ID = uuid.NewString()
+	event.CreatedAt = time.Now().In(u.loc)
+	if event.Actor == "" {
+		event.Actor = entity.GetActorFromContext(ctx)
+	}
+	if event.IP == "" {
+		event.IP = entity.GetRemoteAddressFromContext(ctx)
+	}
+	if event.DeviceID == "" {
+		event.DeviceID = entity.LookupDeviceIdFromContext(ctx)
+	}
+	if event.RequestID == "" {
+		event.RequestID = entity.GetRequestIDFromContext(ctx)
+	}
+
+	if err := u.auditRepository.InsertOne(ctx, event); err != nil {
+		u.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"type":      event.Type,
+			"actor":     event.Actor,
+			"client_id": event.ClientId,
+			"outcome":   event.Outcome,
+		}).Error(err)
+	}
+}
+
+// GetEvents page of the events matching the query, newest first
+func (u *usecase) GetEvents(ctx context.Context, query model.AuditQuery) response.Response {
+	ctx, span := tracing.Start(ctx, "audit.GetEvents")
+	defer span.End()
+
+	filter := bson.M{}
+	if query.Actor != "" {
+		filter["actor"] = query.Actor
+	}
+	if query.ClientId != "" {
+		filter["client_id"] = query.ClientId
+	}
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+
+	createdAt := bson.M{}
+	if query.From != "" {
+		from, _ := time.Parse(time.RFC3339, query.From)
+		createdAt["$gte"] = from
+	}
+	if query.To != "" {
+		to, _ := time.Parse(time.RFC3339, query.To)
+		createdAt["$lt"] = to
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	total, err := u.auditRepository.CountDocuments(ctx, filter)
+	if err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	events, err := u.auditRepository.Find(ctx, filter, int64((query.Page-1)*query.Limit), int64(query.Limit))
+	if err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	meta := model.Pagination{Page: query.Page, Limit: query.Limit, Total: total}
+
+	return response.NewSuccessResponseWithMeta(events, meta, response.StatOK, getAuditEventsSuccessMessage)
+}
+
+func (u *usecase) EnsureIndexes(ctx context.Context) (err error) {
+	return u.auditRepository.EnsureIndexes(ctx, u.retention)
+}
@@ -0,0 +1,86 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	errAssertionExpired     = errors.New("assertion has expired")
+	errAssertionMissingExp  = errors.New("assertion must contain exp")
+	errAssertionMissingJTI  = errors.New("assertion must contain jti")
+	errAssertionNotYetValid = errors.New("assertion is not valid yet")
+	errAssertionAudience    = errors.New("assertion audience is not accepted")
+	errAssertionSubject     = errors.New("assertion iss and sub must be the client id")
+	errAssertionSigningAlg  = errors.New("assertion must be signed with an asymmetric key")
+)
+
+// assertionClockSkewSec tolerated clock difference with the assertion issuer
+const assertionClockSkewSec = 30
+
+// Audience accept aud as a single string or an array
+type Audience []string
+
+// UnmarshalJSON implement json.Unmarshaler
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// Contains report whether one of the values is an audience
+func (a Audience) Contains(values ...string) bool {
+	for _, aud := range a {
+		for _, value := range values {
+			if aud == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AssertionClaims claims of a JWT assertion (RFC 7523 section 3)
+type AssertionClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti"`
+	Scope     string   `json:"scope,omitempty"`
+}
+
+// Valid implement jwt.Claims, exp and jti are mandatory for assertions
+func (c *AssertionClaims) Valid() error {
+	now := time.Now().Unix()
+
+	if c.ExpiresAt == 0 {
+		return errAssertionMissingExp
+	}
+
+	if now > c.ExpiresAt+assertionClockSkewSec {
+		return errAssertionExpired
+	}
+
+	if c.NotBefore != 0 && now+assertionClockSkewSec < c.NotBefore {
+		return errAssertionNotYetValid
+	}
+
+	if c.ID == "" {
+		return errAssertionMissingJTI
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package channel
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+)
+
+// ClientAuthenticator authenticate a channel with the method it registered
+type ClientAuthenticator interface {
+	Authenticate(ctx context.Context, credentials model.ClientAuthentication) (channel entity.Channel, err error)
+	VerifyAssertion(ctx context.Context, channel entity.Channel, assertion string) (claims *AssertionClaims, err error)
+	VerifyRequestObject(ctx context.Context, channel entity.Channel, request string) (claims *RequestObjectClaims, err error)
+	ConsumeRequestObject(ctx context.Context, clientId, id string, expiresAt time.Time) (err error)
+	FetchRequestObject(ctx context.Context, channel entity.Channel, requestURI string) (request string, err error)
+}
+
+type clientAuthenticator struct {
+	logger            *logrus.Logger
+	channelRepository ChannelsRepository
+	replayRepository  ReplayRepository
+	keys              *KeyResolver
+	audiences         []string
+	clientCAs         *x509.CertPool
+}
+
+func NewClientAuthenticator(property ClientAuthenticatorProperty) ClientAuthenticator {
+	return &clientAuthenticator{
+		logger:            property.Logger,
+		channelRepository: property.ChannelsRepository,
+		replayRepository:  property.ReplayRepository,
+		keys:              property.KeyResolver,
+		audiences:         property.Audiences,
+		clientCAs:         property.ClientCAs,
+	}
+}
+
+// Authenticate return exception.ErrNotFound for an unknown client and
+// tokenErr.ErrInvalidClient when the credentials are rejected.
+func (a *clientAuthenticator) Authenticate(ctx context.Context, credentials model.ClientAuthentication) (channel entity.Channel, err error) {
+	channel, err = a.channelRepository.FindByClientId(ctx, credentials.ClientId)
+	if err != nil {
+		return
+	}
+
+	switch channel.AuthMethod() {
+	case entity.ClientSecretPost:
+		if credentials.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(channel.SecretKey), []byte(credentials.ClientSecret)) != 1 {
+			err = tokenErr.ErrInvalidClient
+		}
+	case entity.PrivateKeyJWT:
+		err = a.authenticatePrivateKeyJWT(ctx, channel, credentials)
+	case entity.TLSClientAuth:
+		err = a.authenticateTLSClient(ctx, channel)
+	case entity.SelfSignedTLSClientAuth:
+		err = a.authenticateSelfSignedTLSClient(ctx, channel)
+	default:
+		err = tokenErr.ErrInvalidClient
+	}
+
+	return
+}
+
+func (a *clientAuthenticator) authenticatePrivateKeyJWT(ctx context.Context, channel entity.Channel, credentials model.ClientAuthentication) error {
+	if credentials.ClientAssertionType != entity.ClientAssertionJWTBearer || credentials.ClientAssertion == "" {
+		return tokenErr.ErrInvalidClient
+	}
+
+	claims, err := a.VerifyAssertion(ctx, channel, credentials.ClientAssertion)
+	if err != nil {
+		a.logger.WithContext(ctx).Warn(err)
+		return tokenErr.ErrInvalidClient
+	}
+
+	if claims.Issuer != channel.ClientId || claims.Subject != channel.ClientId {
+		a.logger.WithContext(ctx).Warn(errAssertionSubject)
+		return tokenErr.ErrInvalidClient
+	}
+
+	return nil
+}
+
+// authenticateTLSClient accept a certificate chaining to a trusted CA with the registered subject DN (RFC 8705 section 2.1)
+func (a *clientAuthenticator) authenticateTLSClient(ctx context.Context, channel entity.Channel) error {
+	cert := entity.GetCertificateFromContext(ctx)
+	if cert == nil || a.clientCAs == nil {
+		return tokenErr.ErrInvalidClient
+	}
+
+	// the client may be issued by an intermediate CA it sends along with its certificate
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range entity.GetCertificateIntermediatesFromContext(ctx) {
+		intermediates.AddCert(intermediate)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         a.clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		a.logger.WithContext(ctx).Warn(err)
+		return tokenErr.ErrInvalidClient
+	}
+
+	if cert.Subject.String() != channel.TLSClientAuthSubjectDN {
+		return tokenErr.ErrInvalidClient
+	}
+
+	return nil
+}
+
+// authenticateSelfSignedTLSClient accept the certificate registered by thumbprint (RFC 8705 section 2.2)
+func (a *clientAuthenticator) authenticateSelfSignedTLSClient(ctx context.Context, channel entity.Channel) error {
+	cert := entity.GetCertificateFromContext(ctx)
+	if cert == nil || channel.TLSClientCertificateThumbprint == "" {
+		return tokenErr.ErrInvalidClient
+	}
+
+	thumbprint := entity.CertificateThumbprint(cert)
+	if subtle.ConstantTimeCompare([]byte(thumbprint), []byte(channel.TLSClientCertificateThumbprint)) != 1 {
+		return tokenErr.ErrInvalidClient
+	}
+
+	return nil
+}
+
+// VerifyAssertion check the signature against the channel keys, the audience
+// and consume the jti so the assertion can't be replayed.
+func (a *clientAuthenticator) VerifyAssertion(ctx context.Context, channel entity.Channel, assertion string) (*AssertionClaims, error) {
+	claims := &AssertionClaims{}
+	token, err := jwt.ParseWithClaims(assertion, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, errAssertionSigningAlg
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.PublicKey(ctx, channel, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", tokenErr.ErrInvalidAssertion, err)
+	}
+
+	if !token.Valid {
+		return nil, tokenErr.ErrInvalidAssertion
+	}
+
+	if !claims.Audience.Contains(a.audiences...) {
+		return nil, fmt.Errorf("%w: %v", tokenErr.ErrInvalidAssertion, errAssertionAudience)
+	}
+
+	key := channel.ClientId + ":" + claims.ID
+	expiresAt := time.Unix(claims.ExpiresAt+assertionClockSkewSec, 0)
+	if err := a.replayRepository.Store(ctx, key, expiresAt); err != nil {
+		if err == exception.ErrConflict {
+			return nil, tokenErr.ErrReplayedAssertion
+		}
+		return nil, err
+	}
+
+	return claims, nil
+}
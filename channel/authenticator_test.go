@@ -0,0 +1,128 @@
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+)
+
+const testTokenEndpoint = "https://oauth.example.com/go-oauth/v1/token"
+
+// stubChannelsRepository ChannelsRepository finding the channels of the map
+type stubChannelsRepository struct {
+	ChannelsRepository
+	channels map[string]entity.Channel
+}
+
+func (r stubChannelsRepository) FindByClientId(ctx context.Context, clientId string) (entity.Channel, error) {
+	channel, ok := r.channels[clientId]
+	if !ok {
+		return entity.Channel{}, exception.ErrNotFound
+	}
+	return channel, nil
+}
+
+// jwtClaims jwt.Claims signing any claims, validation is the authenticator's job
+type jwtClaims map[string]interface{}
+
+func (c jwtClaims) Valid() error {
+	return nil
+}
+
+func signAssertion(t *testing.T, key *ecdsa.PrivateKey, kid string, claims jwtClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuthenticatePrivateKeyJWT(t *testing.T) {
+	key, jwk := newTestECKey(t)
+	otherKey, _ := newTestECKey(t)
+	jwks := `{"keys":[{"kty":"EC","kid":"key-1","crv":"P-256","x":"` + jwk.X + `","y":"` + jwk.Y + `"}]}`
+
+	var requests int
+	resolver := NewKeyResolver()
+	resolver.httpClient = countingClient(jwks, &requests)
+
+	authenticator := NewClientAuthenticator(ClientAuthenticatorProperty{
+		Logger: logrus.New(),
+		ChannelsRepository: stubChannelsRepository{channels: map[string]entity.Channel{
+			"client": {ClientId: "client", TokenEndpointAuthMethod: entity.PrivateKeyJWT, JwksURI: "https://client.example/jwks"},
+		}},
+		ReplayRepository: memoryReplayRepository{},
+		KeyResolver:      resolver,
+		Audiences:        []string{testTokenEndpoint},
+	})
+
+	now := time.Now().Unix()
+	var jti int
+	claims := func(overrides jwtClaims) jwtClaims {
+		jti++
+		claims := jwtClaims{"iss": "client", "sub": "client", "aud": testTokenEndpoint, "exp": now + 60, "jti": "jti-" + strconv.Itoa(jti)}
+		for name, value := range overrides {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return claims
+	}
+	hs256, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil)).SignedString([]byte("shared-secret"))
+	replayed := signAssertion(t, key, "key-1", claims(jwtClaims{"jti": "replayed"}))
+
+	tests := []struct {
+		name          string
+		assertionType string
+		assertion     string
+		want          error
+	}{
+		{name: "valid", assertion: signAssertion(t, key, "key-1", claims(nil))},
+		{name: "audience as an array", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"aud": []string{"https://other.example", testTokenEndpoint}}))},
+		{name: "first use", assertion: replayed},
+		{name: "replayed", assertion: replayed, want: tokenErr.ErrInvalidClient},
+		{name: "other assertion type", assertionType: "urn:ietf:params:oauth:client-assertion-type:saml2-bearer", assertion: signAssertion(t, key, "key-1", claims(nil)), want: tokenErr.ErrInvalidClient},
+		{name: "no assertion", want: tokenErr.ErrInvalidClient},
+		{name: "symmetric algorithm", assertion: hs256, want: tokenErr.ErrInvalidClient},
+		{name: "signed by another key", assertion: signAssertion(t, otherKey, "key-1", claims(nil)), want: tokenErr.ErrInvalidClient},
+		{name: "unknown kid", assertion: signAssertion(t, key, "key-2", claims(nil)), want: tokenErr.ErrInvalidClient},
+		{name: "other audience", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"aud": "https://other.example"})), want: tokenErr.ErrInvalidClient},
+		{name: "issuer is another client", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"iss": "other"})), want: tokenErr.ErrInvalidClient},
+		{name: "subject is another client", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"sub": "other"})), want: tokenErr.ErrInvalidClient},
+		{name: "expired", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"exp": now - assertionClockSkewSec - 1})), want: tokenErr.ErrInvalidClient},
+		{name: "missing exp", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"exp": nil})), want: tokenErr.ErrInvalidClient},
+		{name: "missing jti", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"jti": nil})), want: tokenErr.ErrInvalidClient},
+		{name: "not yet valid", assertion: signAssertion(t, key, "key-1", claims(jwtClaims{"nbf": now + assertionClockSkewSec + 60})), want: tokenErr.ErrInvalidClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertionType := entity.ClientAssertionJWTBearer
+			if tt.assertionType != "" {
+				assertionType = tt.assertionType
+			}
+			credentials := model.ClientAuthentication{
+				ClientId:            "client",
+				ClientAssertionType: assertionType,
+				ClientAssertion:     tt.assertion,
+			}
+
+			if _, err := authenticator.Authenticate(context.Background(), credentials); err != tt.want {
+				t.Errorf("Authenticate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
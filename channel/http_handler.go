@@ -27,6 +27,7 @@ func NewChannelHTTPHandler(logger *logrus.Logger, validate *validator.Validate,
 	}
 
 	router.HandleFunc("/go-oauth/v1/channel", basicAuth.Verify(handler.CreateChannel)).Methods(http.MethodPost)
+	router.HandleFunc("/go-oauth/v1/channel/{clientId}/quota", basicAuth.Verify(handler.UpdateQuota)).Methods(http.MethodPut)
 }
 
 func (handler *HTTPHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +52,28 @@ func (handler *HTTPHandler) CreateChannel(w http.ResponseWriter, r *http.Request
 	response.JSON(w, resp)
 }
 
+func (handler *HTTPHandler) UpdateQuota(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.RequestQuota
+	ctx := r.Context()
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp = response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.UpdateQuota(ctx, mux.Vars(r)["clientId"], payload)
+	response.JSON(w, resp)
+}
+
 func (handler *HTTPHandler) validateRequestBody(body interface{}) (err error) {
 	err = handler.Validate.Struct(body)
 	if err == nil {
@@ -0,0 +1,243 @@
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+)
+
+const (
+	jwksCacheTTL     = time.Minute * 5
+	jwksFetchTimeout = time.Second * 10
+	// jwksRefreshInterval time before an unknown kid fetches a key set again,
+	// assertions with made up kids can't have us fetch it on every request
+	jwksRefreshInterval = time.Second * 30
+	// jwksMaxSize a key set is a handful of keys, anything larger is refused
+	jwksMaxSize = 64 << 10
+)
+
+var (
+	errNoClientKey      = errors.New("channel has no registered key")
+	errUnknownKeyID     = errors.New("no matching key found in jwks")
+	errUnsupportedKey   = errors.New("unsupported key type")
+	errInvalidPublicKey = errors.New("invalid public key")
+)
+
+// JSONWebKey public part of a JWK (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JSONWebKeySet JWKS document
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type cachedKeySet struct {
+	keySet    JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// KeyResolver resolve the public key a channel signs its assertions with
+type KeyResolver struct {
+	httpClient *http.Client
+	mu         sync.Mutex
+	cache      map[string]cachedKeySet
+}
+
+// NewKeyResolver is a constructor, key sets are only fetched from public https hosts.
+func NewKeyResolver() *KeyResolver {
+	return &KeyResolver{
+		httpClient: NewPublicHTTPClient(jwksFetchTimeout),
+		cache:      make(map[string]cachedKeySet),
+	}
+}
+
+// PublicKey return the registered PEM key, or the JWKS key matching kid
+func (k *KeyResolver) PublicKey(ctx context.Context, channel entity.Channel, kid string) (interface{}, error) {
+	if channel.PublicKey != "" {
+		return ParsePublicKeyPEM(channel.PublicKey)
+	}
+
+	if channel.JwksURI == "" {
+		return nil, errNoClientKey
+	}
+
+	keySet, err := k.keySet(ctx, channel.JwksURI, false)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keySet.lookup(kid)
+	if err == errUnknownKeyID {
+		// the channel may have rotated its keys since we cached them
+		if keySet, err = k.keySet(ctx, channel.JwksURI, true); err != nil {
+			return nil, err
+		}
+		key, err = keySet.lookup(kid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key.PublicKey()
+}
+
+func (k *KeyResolver) keySet(ctx context.Context, uri string, refresh bool) (JSONWebKeySet, error) {
+	k.mu.Lock()
+	cached, ok := k.cache[uri]
+	k.mu.Unlock()
+
+	if ok && !refresh && time.Since(cached.fetchedAt) < jwksCacheTTL {
+		return cached.keySet, nil
+	}
+	if ok && refresh && time.Since(cached.fetchedAt) < jwksRefreshInterval {
+		return cached.keySet, nil
+	}
+
+	body, err := getPublic(ctx, k.httpClient, uri, "application/json", jwksMaxSize)
+	if err != nil {
+		return JSONWebKeySet{}, fmt.Errorf("fetch jwks %s: %w", uri, err)
+	}
+
+	var keySet JSONWebKeySet
+	if err := json.Unmarshal(body, &keySet); err != nil {
+		return JSONWebKeySet{}, fmt.Errorf("fetch jwks %s: %w", uri, err)
+	}
+
+	now := time.Now()
+	k.mu.Lock()
+	k.evictStale(now)
+	k.cache[uri] = cachedKeySet{keySet: keySet, fetchedAt: now}
+	k.mu.Unlock()
+
+	return keySet, nil
+}
+
+// evictStale drop the key sets past their ttl, channels deleted or moved to
+// another jwks_uri don't stay cached forever. The caller holds the lock.
+func (k *KeyResolver) evictStale(now time.Time) {
+	for uri, cached := range k.cache {
+		if now.Sub(cached.fetchedAt) >= jwksCacheTTL {
+			delete(k.cache, uri)
+		}
+	}
+}
+
+func (s JSONWebKeySet) lookup(kid string) (JSONWebKey, error) {
+	for _, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if kid == "" || key.Kid == kid {
+			return key, nil
+		}
+	}
+	return JSONWebKey{}, errUnknownKeyID
+}
+
+// PublicKey convert the JWK into *rsa.PublicKey or *ecdsa.PublicKey
+func (j JSONWebKey) PublicKey() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, errInvalidPublicKey
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, errInvalidPublicKey
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, errInvalidPublicKey
+		}
+		y, err := base64.RawURLEncoding.DecodeString(j.Y)
+		if err != nil {
+			return nil, errInvalidPublicKey
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+// Thumbprint base64url SHA-256 of the required members of the key (RFC 7638)
+func (j JSONWebKey) Thumbprint() (string, error) {
+	var members string
+	switch j.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Crv, j.X, j.Y)
+	default:
+		return "", errUnsupportedKey
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// ParsePublicKeyPEM parse a PKIX or PKCS1 encoded public key
+func ParsePublicKeyPEM(data string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errInvalidPublicKey
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return cert.PublicKey, nil
+	}
+
+	return nil, errInvalidPublicKey
+}
@@ -0,0 +1,86 @@
+package channel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+)
+
+// roundTripFunc serve the requests of a test http client without a network
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// countingClient client answering every request with the body, counting the requests
+func countingClient(body string, requests *int) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		*requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+}
+
+func newTestECKey(t *testing.T) (*ecdsa.PrivateKey, JSONWebKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, JSONWebKey{
+		Kty: "EC",
+		Kid: "key-1",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func TestUnknownKidRefetchesAtMostEveryInterval(t *testing.T) {
+	ctx := context.Background()
+	_, jwk := newTestECKey(t)
+	jwks := `{"keys":[{"kty":"EC","kid":"key-1","crv":"P-256","x":"` + jwk.X + `","y":"` + jwk.Y + `"}]}`
+
+	var requests int
+	resolver := NewKeyResolver()
+	resolver.httpClient = countingClient(jwks, &requests)
+	channel := entity.Channel{ClientId: "client", JwksURI: "https://client.example/jwks"}
+
+	if _, err := resolver.PublicKey(ctx, channel, "key-1"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := resolver.PublicKey(ctx, channel, "made-up"); err != errUnknownKeyID {
+			t.Fatalf("unknown kid: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d fetches for unknown kids right after a fetch, want 1", requests)
+	}
+
+	// past the interval a rotated key set is fetched again
+	resolver.mu.Lock()
+	cached := resolver.cache[channel.JwksURI]
+	cached.fetchedAt = time.Now().Add(-jwksRefreshInterval)
+	resolver.cache[channel.JwksURI] = cached
+	resolver.mu.Unlock()
+
+	if _, err := resolver.PublicKey(ctx, channel, "made-up"); err != errUnknownKeyID {
+		t.Fatalf("unknown kid: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("%d fetches, want the key set fetched again past the interval", requests)
+	}
+}
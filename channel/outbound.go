@@ -0,0 +1,126 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const maxRedirects = 3
+
+var (
+	errInsecureURI      = errors.New("only https uris are fetched")
+	errForbiddenAddress = errors.New("address is not publicly routable")
+	errTooManyRedirects = errors.New("too many redirects")
+	errResponseTooLarge = errors.New("response exceeds the size limit")
+)
+
+// NewPublicHTTPClient http client for the uris channels register (jwks_uri,
+// request_uri, backchannel_logout_uri). Only https is used and the address is
+// checked when connecting, after name resolution, so neither a registered uri
+// nor a redirect or a rebinding DNS answer can reach loopback, private or
+// link-local hosts of our network.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%s: %w", address, errForbiddenAddress)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errTooManyRedirects
+			}
+			if req.URL.Scheme != "https" {
+				return errInsecureURI
+			}
+			return nil
+		},
+	}
+}
+
+// IsPublicAddress the address is routable on the internet, not loopback,
+// private, link-local, multicast, unspecified or shared address space
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	// carrier-grade NAT (RFC 6598) is as internal as the private ranges
+	return !netip.MustParsePrefix("100.64.0.0/10").Contains(addr)
+}
+
+// validatePublicURI the uri is an https url whose host isn't a literal internal address
+func validatePublicURI(uri string) error {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return errInsecureURI
+	}
+	if addr, err := netip.ParseAddr(parsed.Hostname()); err == nil && !IsPublicAddress(addr) {
+		return errForbiddenAddress
+	}
+	return nil
+}
+
+// getPublic GET the uri with the client, the body is read up to maxSize bytes
+func getPublic(ctx context.Context, client *http.Client, uri, accept string, maxSize int64) ([]byte, error) {
+	if err := validatePublicURI(uri); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return readLimited(resp.Body, maxSize)
+}
+
+// readLimited read the whole body, refusing one larger than maxSize bytes rather than truncating it
+func readLimited(body io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errResponseTooLarge
+	}
+	return data, nil
+}
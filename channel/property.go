@@ -1,6 +1,7 @@
 package channel
 
 import (
+	"crypto/x509"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,4 +12,21 @@ type UsecaseChannelProperty struct {
 	Logger             *logrus.Logger
 	Location           *time.Location
 	ChannelsRepository ChannelsRepository
+	// RegistrationURI base of the client configuration endpoint of dynamic registration
+	RegistrationURI string
+	// RegistrationScopes scopes a dynamically registered channel can ask for
+	RegistrationScopes []string
+	// AuditRecorder optional, records who created, changed or deleted a channel
+	AuditRecorder AuditRecorder
+}
+
+type ClientAuthenticatorProperty struct {
+	Logger             *logrus.Logger
+	ChannelsRepository ChannelsRepository
+	ReplayRepository   ReplayRepository
+	KeyResolver        *KeyResolver
+	// Audiences accepted in the aud claim of client assertions, usually the token endpoint
+	Audiences []string
+	// ClientCAs trusted roots of tls_client_auth certificates
+	ClientCAs *x509.CertPool
 }
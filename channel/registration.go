@@ -0,0 +1,389 @@
+package channel
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	registerClientSuccessMessage  = "Register Client Successfully"
+	getClientSuccessMessage       = "Get Client Successfully"
+	updateClientSuccessMessage    = "Update Client Successfully"
+	deleteClientSuccessMessage    = "Delete Client Successfully"
+	errorRegisterClientMessage    = "Register Client Failed!"
+	errorRegistrationTokenMessage = "Invalid registration access token"
+	errorRedirectURIsMessage      = "redirect_uris is required by the authorization_code grant"
+	errorGrantTypesMessage        = "grant_types contains a grant type that can't be registered"
+	errorAuthMethodMessage        = "token_endpoint_auth_method is not supported"
+	errorJwksURIMessage           = "jwks_uri is required by private_key_jwt"
+	errorSubjectDNMessage         = "tls_client_auth_subject_dn is required by tls_client_auth"
+	errorOpenIDSecretMessage      = "openid and backchannel_logout_uri require client_secret_post, id tokens and logout tokens are signed with the client secret"
+	errorClientIdMessage          = "client_id does not match the registered client"
+	errorRedirectURIMessage       = "redirect_uris and post_logout_redirect_uris must be https without a fragment, or a loopback or private-use scheme uri of a native app"
+	errorClientURIMessage         = "jwks_uri, request_uris and backchannel_logout_uri must be public https uris without a fragment"
+)
+
+// grant types a channel can give itself through dynamic registration, the
+// others need a policy only an administrator can set
+var registrableGrantTypes = []entity.GrantType{entity.AuthorizationCode, entity.ClientCredentials, entity.DeviceCode, entity.Refreshing}
+
+// RegisterClient create a channel from client metadata (RFC 7591 section 3)
+func (u *usecase) RegisterClient(ctx context.Context, payload model.ClientMetadata) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.RegisterClient")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	metadata, scopes, resp := u.validateMetadata(payload)
+	if resp != nil {
+		return resp
+	}
+
+	token, err := generateRegistrationAccessToken()
+	if err != nil {
+		u.logger.WithContext(ctx).Error(err)
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorRegisterClientMessage)
+	}
+
+	channelID := uuid.NewString()
+	channel := entity.Channel{
+		ID:                                 channelID,
+		Name:                               metadata.ClientName,
+		ClientId:                           u.generateClientId(metadata.ClientName),
+		IsActive:                           true,
+		ClientType:                         "confidential",
+		GrantTypes:                         metadata.GrantTypes,
+		Scopes:                             scopes,
+		TokenEndpointAuthMethod:            metadata.TokenEndpointAuthMethod,
+		JwksURI:                            metadata.JwksURI,
+		TLSClientAuthSubjectDN:             metadata.TLSClientAuthSubjectDN,
+		RequirePushedAuthorizationRequests: metadata.RequirePushedAuthorizationRequests,
+		RequireSignedRequestObject:         metadata.RequireSignedRequestObject,
+		RequestURIs:                        metadata.RequestURIs,
+		PostLogoutRedirectURIs:             metadata.PostLogoutRedirectURIs,
+		BackchannelLogoutURI:               metadata.BackchannelLogoutURI,
+		RegistrationAccessTokenHash:        hashRegistrationAccessToken(token),
+		CreatedAt:                          now,
+		UpdatedAt:                          now,
+	}
+	if metadata.TokenEndpointAuthMethod == entity.ClientSecretPost {
+		channel.SecretKey = u.generateSecretKey(channelID)
+	}
+	if len(metadata.RedirectURIs) > 0 {
+		channel.RedirectURI = metadata.RedirectURIs[0]
+		channel.RedirectURIs = metadata.RedirectURIs[1:]
+	}
+
+	if err := u.channelRepository.InsertOne(ctx, channel); err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorRegisterClientMessage)
+	}
+
+	// a registered client manages itself, it is the actor of its own changes
+	u.record(ctx, entity.AuditChannelCreated, channel.ClientId, channel.ClientId)
+
+	data := u.clientInformation(channel)
+	data.RegistrationAccessToken = token
+
+	return response.NewSuccessResponse(data, response.StatCreated, registerClientSuccessMessage)
+}
+
+// ReadClient current configuration of the client (RFC 7592 section 2.1)
+func (u *usecase) ReadClient(ctx context.Context, clientId, token string) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.ReadClient")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, clientId)
+
+	channel, resp := u.findRegisteredClient(ctx, clientId, token)
+	if resp != nil {
+		return resp
+	}
+
+	return response.NewSuccessResponse(u.clientInformation(channel), response.StatOK, getClientSuccessMessage)
+}
+
+// UpdateClient replace the metadata of the client, the credentials are kept (RFC 7592 section 2.2)
+func (u *usecase) UpdateClient(ctx context.Context, clientId, token string, payload model.ClientInformation) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.UpdateClient")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, clientId)
+
+	channel, resp := u.findRegisteredClient(ctx, clientId, token)
+	if resp != nil {
+		return resp
+	}
+
+	if payload.ClientId != channel.ClientId ||
+		(payload.ClientSecret != "" && subtle.ConstantTimeCompare([]byte(payload.ClientSecret), []byte(channel.SecretKey)) != 1) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorClientIdMessage)
+	}
+
+	metadata, scopes, resp := u.validateMetadata(payload.ClientMetadata)
+	if resp != nil {
+		return resp
+	}
+
+	channel.Name = metadata.ClientName
+	channel.GrantTypes = metadata.GrantTypes
+	channel.Scopes = scopes
+	channel.JwksURI = metadata.JwksURI
+	channel.TLSClientAuthSubjectDN = metadata.TLSClientAuthSubjectDN
+	channel.RequirePushedAuthorizationRequests = metadata.RequirePushedAuthorizationRequests
+	channel.RequireSignedRequestObject = metadata.RequireSignedRequestObject
+	channel.RequestURIs = metadata.RequestURIs
+	channel.PostLogoutRedirectURIs = metadata.PostLogoutRedirectURIs
+	channel.BackchannelLogoutURI = metadata.BackchannelLogoutURI
+	channel.RedirectURI, channel.RedirectURIs = "", nil
+	if len(metadata.RedirectURIs) > 0 {
+		channel.RedirectURI = metadata.RedirectURIs[0]
+		channel.RedirectURIs = metadata.RedirectURIs[1:]
+	}
+	if metadata.TokenEndpointAuthMethod != channel.AuthMethod() {
+		channel.TokenEndpointAuthMethod = metadata.TokenEndpointAuthMethod
+		channel.SecretKey = ""
+		if metadata.TokenEndpointAuthMethod == entity.ClientSecretPost {
+			channel.SecretKey = u.generateSecretKey(channel.ID)
+		}
+	}
+	channel.UpdatedAt = time.Now().In(u.loc)
+
+	fields := bson.M{
+		"name":                                  channel.Name,
+		"grant_types":                           channel.GrantTypes,
+		"scopes":                                channel.Scopes,
+		"redirect_uri":                          channel.RedirectURI,
+		"redirect_uris":                         channel.RedirectURIs,
+		"token_endpoint_auth_method":            channel.TokenEndpointAuthMethod,
+		"secret_key":                            channel.SecretKey,
+		"jwks_uri":                              channel.JwksURI,
+		"tls_client_auth_subject_dn":            channel.TLSClientAuthSubjectDN,
+		"require_pushed_authorization_requests": channel.RequirePushedAuthorizationRequests,
+		"require_signed_request_object":         channel.RequireSignedRequestObject,
+		"request_uris":                          channel.RequestURIs,
+		"post_logout_redirect_uris":             channel.PostLogoutRedirectURIs,
+		"backchannel_logout_uri":                channel.BackchannelLogoutURI,
+		"updated_at":                            channel.UpdatedAt,
+	}
+	if err := u.channelRepository.UpdateOne(ctx, channel.ID, fields); err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	u.record(ctx, entity.AuditChannelUpdated, channel.ClientId, channel.ClientId)
+
+	return response.NewSuccessResponse(u.clientInformation(channel), response.StatOK, updateClientSuccessMessage)
+}
+
+// DeleteClient deprovision the client (RFC 7592 section 2.3)
+func (u *usecase) DeleteClient(ctx context.Context, clientId, token string) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.DeleteClient")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, clientId)
+
+	channel, resp := u.findRegisteredClient(ctx, clientId, token)
+	if resp != nil {
+		return resp
+	}
+
+	if err := u.channelRepository.DeleteOne(ctx, channel.ID); err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	u.record(ctx, entity.AuditChannelDeleted, channel.ClientId, channel.ClientId)
+
+	return response.NewSuccessResponse("", response.StatOK, deleteClientSuccessMessage)
+}
+
+// findRegisteredClient an unknown client and a wrong token get the same
+// answer so the endpoint can't be used to probe client ids
+func (u *usecase) findRegisteredClient(ctx context.Context, clientId, token string) (entity.Channel, response.Response) {
+	channel, err := u.channelRepository.FindByClientId(ctx, clientId)
+	if err != nil && err != exception.ErrNotFound {
+		return channel, response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	hash := hashRegistrationAccessToken(token)
+	if err == exception.ErrNotFound || channel.RegistrationAccessTokenHash == "" ||
+		subtle.ConstantTimeCompare([]byte(channel.RegistrationAccessTokenHash), []byte(hash)) != 1 {
+		return channel, response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorRegistrationTokenMessage)
+	}
+
+	return channel, nil
+}
+
+// validateMetadata apply the defaults of RFC 7591 section 2 and reject what
+// this server can't honour
+func (u *usecase) validateMetadata(metadata model.ClientMetadata) (model.ClientMetadata, []string, response.Response) {
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = []entity.GrantType{entity.AuthorizationCode}
+	}
+	if metadata.TokenEndpointAuthMethod == "" {
+		metadata.TokenEndpointAuthMethod = entity.ClientSecretPost
+	}
+
+	for _, grantType := range metadata.GrantTypes {
+		if !containsGrantType(registrableGrantTypes, grantType) {
+			return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorGrantTypesMessage)
+		}
+	}
+
+	channel := entity.Channel{GrantTypes: metadata.GrantTypes}
+	if channel.HasGrantType(entity.AuthorizationCode) && len(metadata.RedirectURIs) == 0 {
+		return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidRedirectURIs, http.StatusBadRequest, nil, response.StatInvalidRedirectURI, errorRedirectURIsMessage)
+	}
+
+	for _, uri := range append(append([]string{}, metadata.RedirectURIs...), metadata.PostLogoutRedirectURIs...) {
+		if !validRedirectURI(uri) {
+			return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidRedirectURIs, http.StatusBadRequest, nil, response.StatInvalidRedirectURI, errorRedirectURIMessage)
+		}
+	}
+
+	// the server fetches or posts to these, they must not reach our own network
+	for _, uri := range append([]string{metadata.JwksURI, metadata.BackchannelLogoutURI}, metadata.RequestURIs...) {
+		if uri != "" && !validClientURI(uri) {
+			return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorClientURIMessage)
+		}
+	}
+
+	switch metadata.TokenEndpointAuthMethod {
+	case entity.ClientSecretPost:
+	case entity.PrivateKeyJWT:
+		if metadata.JwksURI == "" {
+			return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorJwksURIMessage)
+		}
+	case entity.TLSClientAuth:
+		if metadata.TLSClientAuthSubjectDN == "" {
+			return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorSubjectDNMessage)
+		}
+	default:
+		return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorAuthMethodMessage)
+	}
+
+	scopes := u.registrationScopes
+	if metadata.Scope != "" {
+		scopes = strings.Fields(metadata.Scope)
+	}
+	allowed := entity.Channel{Scopes: u.registrationScopes}
+	if !allowed.HasScopes(scopes) {
+		return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, tokenErr.ErrInvalidScope.Error())
+	}
+
+	// ID tokens and logout tokens are signed with the client secret, the other
+	// methods leave the client without one
+	if metadata.TokenEndpointAuthMethod != entity.ClientSecretPost {
+		if metadata.Scope == "" {
+			scopes = withoutScope(scopes, entity.ScopeOpenID)
+		}
+		if containsScope(scopes, entity.ScopeOpenID) || metadata.BackchannelLogoutURI != "" {
+			return metadata, nil, response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, errorOpenIDSecretMessage)
+		}
+	}
+
+	return metadata, scopes, nil
+}
+
+func (u *usecase) clientInformation(channel entity.Channel) model.ClientInformation {
+	return model.ClientInformation{
+		ClientId:              channel.ClientId,
+		ClientSecret:          channel.SecretKey,
+		ClientIdIssuedAt:      channel.CreatedAt.Unix(),
+		RegistrationClientURI: u.registrationURI + "/" + channel.ClientId,
+		ClientMetadata: model.ClientMetadata{
+			ClientName:                         channel.Name,
+			RedirectURIs:                       channel.AllRedirectURIs(),
+			GrantTypes:                         channel.GrantTypes,
+			TokenEndpointAuthMethod:            channel.AuthMethod(),
+			Scope:                              strings.Join(channel.Scopes, " "),
+			JwksURI:                            channel.JwksURI,
+			TLSClientAuthSubjectDN:             channel.TLSClientAuthSubjectDN,
+			RequirePushedAuthorizationRequests: channel.RequirePushedAuthorizationRequests,
+			RequireSignedRequestObject:         channel.RequireSignedRequestObject,
+			RequestURIs:                        channel.RequestURIs,
+			PostLogoutRedirectURIs:             channel.PostLogoutRedirectURIs,
+			BackchannelLogoutURI:               channel.BackchannelLogoutURI,
+		},
+	}
+}
+
+// validRedirectURI an absolute https uri without a fragment (RFC 6749 section
+// 3.1.2). Native apps may also use http on a loopback address or a private-use
+// scheme in reverse domain notation (RFC 8252 sections 7.1 and 7.3).
+func validRedirectURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Fragment != "" || strings.Contains(uri, "#") {
+		return false
+	}
+
+	switch {
+	case parsed.Scheme == "https":
+		return parsed.Host != ""
+	case parsed.Scheme == "http":
+		ip := net.ParseIP(parsed.Hostname())
+		return ip != nil && ip.IsLoopback()
+	default:
+		return strings.Contains(parsed.Scheme, ".")
+	}
+}
+
+// validClientURI a public https uri without a fragment
+func validClientURI(uri string) bool {
+	if strings.Contains(uri, "#") {
+		return false
+	}
+	return validatePublicURI(uri) == nil
+}
+
+func containsGrantType(grantTypes []entity.GrantType, grantType entity.GrantType) bool {
+	channel := entity.Channel{GrantTypes: grantTypes}
+	return channel.HasGrantType(grantType)
+}
+
+func containsScope(scopes []string, scope string) bool {
+	channel := entity.Channel{Scopes: scopes}
+	return channel.HasScopes([]string{scope})
+}
+
+// withoutScope copy of the scopes without the scope
+func withoutScope(scopes []string, scope string) []string {
+	kept := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if s != scope {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func generateRegistrationAccessToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashRegistrationAccessToken only the hash is stored, a database leak doesn't leak the tokens
+func hashRegistrationAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/middleware"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	// RegistrationPath dynamic client registration endpoint
+	RegistrationPath = "/go-oauth/v1/register"
+)
+
+type RegistrationHTTPHandler struct {
+	Logger   *logrus.Logger
+	Validate *validator.Validate
+	Usecase  Usecase
+}
+
+// NewRegistrationHTTPHandler register the client registration endpoint, gated
+// by the initial access token, and the client configuration endpoint, gated
+// by the registration access token of each client.
+func NewRegistrationHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, initialAccess middleware.RouteMiddleware, usecase Usecase) {
+	handler := &RegistrationHTTPHandler{
+		Logger:   logger,
+		Validate: validate,
+		Usecase:  usecase,
+	}
+
+	router.HandleFunc(RegistrationPath, initialAccess.Verify(handler.RegisterClient)).Methods(http.MethodPost)
+	router.HandleFunc(RegistrationPath+"/{clientId}", handler.ReadClient).Methods(http.MethodGet)
+	router.HandleFunc(RegistrationPath+"/{clientId}", handler.UpdateClient).Methods(http.MethodPut)
+	router.HandleFunc(RegistrationPath+"/{clientId}", handler.DeleteClient).Methods(http.MethodDelete)
+}
+
+func (handler *RegistrationHTTPHandler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.ClientMetadata
+	ctx := r.Context()
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp = response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.RegisterClient(ctx, payload)
+	response.JSON(w, resp)
+}
+
+func (handler *RegistrationHTTPHandler) ReadClient(w http.ResponseWriter, r *http.Request) {
+	token, ok := middleware.BearerToken(r)
+	if !ok {
+		handler.respondUnauthorized(w)
+		return
+	}
+
+	resp := handler.Usecase.ReadClient(r.Context(), mux.Vars(r)["clientId"], token)
+	response.JSON(w, resp)
+}
+
+func (handler *RegistrationHTTPHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.ClientInformation
+	ctx := r.Context()
+
+	token, ok := middleware.BearerToken(r)
+	if !ok {
+		handler.respondUnauthorized(w)
+		return
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload.ClientMetadata); err != nil {
+		resp = response.NewErrorResponse(tokenErr.ErrInvalidClientMetadata, http.StatusBadRequest, nil, response.StatInvalidClientMeta, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.UpdateClient(ctx, mux.Vars(r)["clientId"], token, payload)
+	response.JSON(w, resp)
+}
+
+func (handler *RegistrationHTTPHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	token, ok := middleware.BearerToken(r)
+	if !ok {
+		handler.respondUnauthorized(w)
+		return
+	}
+
+	resp := handler.Usecase.DeleteClient(r.Context(), mux.Vars(r)["clientId"], token)
+	response.JSON(w, resp)
+}
+
+func (handler *RegistrationHTTPHandler) respondUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	resp := response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorRegistrationTokenMessage)
+	response.JSON(w, resp)
+}
+
+func (handler *RegistrationHTTPHandler) validateRequestBody(body interface{}) (err error) {
+	err = handler.Validate.Struct(body)
+	if err == nil {
+		return
+	}
+
+	errorFields := err.(validator.ValidationErrors)
+	errorField := errorFields[0]
+	err = fmt.Errorf("invalid '%s' with value '%v'", errorField.Field(), errorField.Value())
+
+	return
+}
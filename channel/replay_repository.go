@@ -0,0 +1,55 @@
+package channel
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReplayRepository remember single-use identifiers (jti) until they expire
+type ReplayRepository interface {
+	// Store return exception.ErrConflict when the key has been stored before
+	Store(ctx context.Context, key string, expiresAt time.Time) (err error)
+	// EnsureIndexes create the index removing the identifiers once they expire
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type replayEntry struct {
+	Key       string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type replayRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewReplayRepository(logger *logrus.Logger, db mongodb.Database) ReplayRepository {
+	col := db.Collection("oauth_jti")
+	return &replayRepository{logger, col}
+}
+
+// Store rely on the unique _id so concurrent replays across replicas are rejected atomically
+func (r *replayRepository) Store(ctx context.Context, key string, expiresAt time.Time) (err error) {
+	if _, err = r.col.InsertOne(ctx, replayEntry{Key: key, ExpiresAt: expiresAt}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = exception.ErrConflict
+			return
+		}
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *replayRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.TTLIndex("expires_at", 0)); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
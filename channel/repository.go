@@ -15,6 +15,9 @@ import (
 type ChannelsRepository interface {
 	InsertOne(ctx context.Context, entryData entity.Channel) (err error)
 	FindOne(ctx context.Context, payload model.TokenRequest) (channel entity.Channel, err error)
+	FindByClientId(ctx context.Context, clientId string) (channel entity.Channel, err error)
+	UpdateOne(ctx context.Context, id string, fields bson.M) (err error)
+	DeleteOne(ctx context.Context, id string) (err error)
 }
 
 type channelRepository struct {
@@ -30,23 +33,27 @@ func NewChannelRepository(logger *logrus.Logger, db mongodb.Database) ChannelsRe
 func (r *channelRepository) InsertOne(ctx context.Context, entryData entity.Channel) (err error) {
 	resp, err := r.col.InsertOne(ctx, entryData)
 	if err != nil {
-		r.logger.Error(err)
+		r.logger.WithContext(ctx).Error(err)
 		err = exception.ErrInternalServer
 		return
 	}
 
-	r.logger.Infoln("logId", resp.InsertedID)
+	r.logger.WithContext(ctx).Infoln("logId", resp.InsertedID)
 	return
 }
 
 func (r *channelRepository) FindOne(ctx context.Context, payload model.TokenRequest) (channel entity.Channel, err error) {
+	return r.FindByClientId(ctx, payload.ClientId)
+}
+
+func (r *channelRepository) FindByClientId(ctx context.Context, clientId string) (channel entity.Channel, err error) {
 	filter := bson.M{
-		"client_id": payload.ClientId,
+		"client_id": clientId,
 	}
 
 	if err = r.col.FindOne(ctx, filter).Decode(&channel); err != nil {
 		if err != mongo.ErrNoDocuments {
-			r.logger.Error(err)
+			r.logger.WithContext(ctx).Error(err)
 			err = exception.ErrInternalServer
 			return
 		}
@@ -56,3 +63,31 @@ func (r *channelRepository) FindOne(ctx context.Context, payload model.TokenRequ
 
 	return
 }
+
+func (r *channelRepository) UpdateOne(ctx context.Context, id string, fields bson.M) (err error) {
+	resp, err := r.col.UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": fields})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.MatchedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *channelRepository) DeleteOne(ctx context.Context, id string) (err error) {
+	resp, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
@@ -0,0 +1,147 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+const (
+	// requestObjectMaxSize upper bound of a request object fetched by reference
+	requestObjectMaxSize = 64 << 10
+	// requestObjectMaxLifetime longest span between nbf and exp of a request object
+	requestObjectMaxLifetime = 60 * time.Minute
+	// requestObjectReplayPrefix keeps the jti of request objects apart from those of assertions
+	requestObjectReplayPrefix = "request_object:"
+)
+
+var (
+	errRequestObjectExpired     = errors.New("request object has expired")
+	errRequestObjectNotYetValid = errors.New("request object is not valid yet")
+	errRequestObjectMissingExp  = errors.New("request object must contain exp")
+	errRequestObjectMissingNbf  = errors.New("request object must contain nbf")
+	errRequestObjectMissingJTI  = errors.New("request object must contain jti")
+	errRequestObjectLifetime    = errors.New("request object must not be valid for more than 60 minutes")
+	errRequestObjectClient      = errors.New("request object iss and client_id must be the client id")
+	errRequestURINotRegistered  = errors.New("request_uri is not registered by the channel")
+)
+
+// RequestObjectClaims authorization request parameters of a signed request object (RFC 9101 section 4)
+type RequestObjectClaims struct {
+	Issuer              string   `json:"iss"`
+	Audience            Audience `json:"aud"`
+	ExpiresAt           int64    `json:"exp,omitempty"`
+	NotBefore           int64    `json:"nbf,omitempty"`
+	IssuedAt            int64    `json:"iat,omitempty"`
+	ID                  string   `json:"jti"`
+	ClientId            string   `json:"client_id"`
+	ResponseType        string   `json:"response_type"`
+	RedirectURI         string   `json:"redirect_uri"`
+	Scope               string   `json:"scope"`
+	State               string   `json:"state"`
+	Nonce               string   `json:"nonce"`
+	CodeChallenge       string   `json:"code_challenge"`
+	CodeChallengeMethod string   `json:"code_challenge_method"`
+}
+
+// Valid implement jwt.Claims. exp, nbf and jti are mandatory and the object
+// can't be valid for more than an hour, a captured one is only replayable
+// for a short while and only once.
+func (c *RequestObjectClaims) Valid() error {
+	now := time.Now().Unix()
+
+	if c.ExpiresAt == 0 {
+		return errRequestObjectMissingExp
+	}
+
+	if c.NotBefore == 0 {
+		return errRequestObjectMissingNbf
+	}
+
+	if c.ID == "" {
+		return errRequestObjectMissingJTI
+	}
+
+	if c.ExpiresAt-c.NotBefore > int64(requestObjectMaxLifetime/time.Second) {
+		return errRequestObjectLifetime
+	}
+
+	if now > c.ExpiresAt+assertionClockSkewSec {
+		return errRequestObjectExpired
+	}
+
+	if now+assertionClockSkewSec < c.NotBefore {
+		return errRequestObjectNotYetValid
+	}
+
+	return nil
+}
+
+// VerifyRequestObject check the request object is signed by the channel and meant for this server
+func (a *clientAuthenticator) VerifyRequestObject(ctx context.Context, channel entity.Channel, request string) (*RequestObjectClaims, error) {
+	claims := &RequestObjectClaims{}
+	token, err := jwt.ParseWithClaims(request, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, errAssertionSigningAlg
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.PublicKey(ctx, channel, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", tokenErr.ErrInvalidRequestObject, err)
+	}
+
+	if !token.Valid {
+		return nil, tokenErr.ErrInvalidRequestObject
+	}
+
+	if !claims.Audience.Contains(a.audiences...) {
+		return nil, fmt.Errorf("%w: %v", tokenErr.ErrInvalidRequestObject, errAssertionAudience)
+	}
+
+	if (claims.Issuer != "" && claims.Issuer != channel.ClientId) || (claims.ClientId != "" && claims.ClientId != channel.ClientId) {
+		return nil, fmt.Errorf("%w: %v", tokenErr.ErrInvalidRequestObject, errRequestObjectClient)
+	}
+
+	return claims, nil
+}
+
+// ConsumeRequestObject record the jti of a request object once it is used,
+// tokenErr.ErrInvalidRequestObject when it was used before
+func (a *clientAuthenticator) ConsumeRequestObject(ctx context.Context, clientId, id string, expiresAt time.Time) error {
+	key := requestObjectReplayPrefix + clientId + ":" + id
+	if err := a.replayRepository.Store(ctx, key, expiresAt.Add(assertionClockSkewSec*time.Second)); err != nil {
+		if err == exception.ErrConflict {
+			return tokenErr.ErrInvalidRequestObject
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FetchRequestObject download a request object passed by reference. Only the
+// https uris the channel registered are fetched, never an arbitrary host.
+func (a *clientAuthenticator) FetchRequestObject(ctx context.Context, channel entity.Channel, requestURI string) (string, error) {
+	uri, err := url.Parse(requestURI)
+	if err != nil || uri.Scheme != "https" || !channel.HasRequestURI(requestURI) {
+		return "", fmt.Errorf("%w: %v", tokenErr.ErrInvalidRequestURI, errRequestURINotRegistered)
+	}
+
+	body, err := getPublic(ctx, a.keys.httpClient, requestURI, "application/oauth-authz-req+jwt", requestObjectMaxSize)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", tokenErr.ErrInvalidRequestURI, err)
+	}
+
+	return string(body), nil
+}
@@ -0,0 +1,154 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+// memoryReplayRepository ReplayRepository keeping the keys in a map
+type memoryReplayRepository map[string]time.Time
+
+func (r memoryReplayRepository) Store(ctx context.Context, key string, expiresAt time.Time) error {
+	if _, ok := r[key]; ok {
+		return exception.ErrConflict
+	}
+	r[key] = expiresAt
+	return nil
+}
+
+func (r memoryReplayRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func TestRequestObjectClaimsValid(t *testing.T) {
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name   string
+		claims RequestObjectClaims
+		want   error
+	}{
+		{
+			name:   "valid",
+			claims: RequestObjectClaims{ID: "1", NotBefore: now, ExpiresAt: now + 300},
+		},
+		{
+			name:   "missing exp",
+			claims: RequestObjectClaims{ID: "1", NotBefore: now},
+			want:   errRequestObjectMissingExp,
+		},
+		{
+			name:   "missing nbf",
+			claims: RequestObjectClaims{ID: "1", ExpiresAt: now + 300},
+			want:   errRequestObjectMissingNbf,
+		},
+		{
+			name:   "missing jti",
+			claims: RequestObjectClaims{NotBefore: now, ExpiresAt: now + 300},
+			want:   errRequestObjectMissingJTI,
+		},
+		{
+			name:   "lifetime over 60 minutes",
+			claims: RequestObjectClaims{ID: "1", NotBefore: now, ExpiresAt: now + 3601},
+			want:   errRequestObjectLifetime,
+		},
+		{
+			name:   "expired",
+			claims: RequestObjectClaims{ID: "1", NotBefore: now - 600, ExpiresAt: now - assertionClockSkewSec - 1},
+			want:   errRequestObjectExpired,
+		},
+		{
+			name:   "expired within the clock skew",
+			claims: RequestObjectClaims{ID: "1", NotBefore: now - 600, ExpiresAt: now - assertionClockSkewSec + 5},
+		},
+		{
+			name:   "not yet valid",
+			claims: RequestObjectClaims{ID: "1", NotBefore: now + assertionClockSkewSec + 60, ExpiresAt: now + 600},
+			want:   errRequestObjectNotYetValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.claims.Valid(); err != tt.want {
+				t.Errorf("Valid() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsumeRequestObjectReplay(t *testing.T) {
+	ctx := context.Background()
+	replay := memoryReplayRepository{}
+	authenticator := &clientAuthenticator{replayRepository: replay}
+	expiresAt := time.Now().Add(5 * time.Minute)
+
+	if err := authenticator.ConsumeRequestObject(ctx, "client", "jti-1", expiresAt); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+
+	if err := authenticator.ConsumeRequestObject(ctx, "client", "jti-1", expiresAt); err != tokenErr.ErrInvalidRequestObject {
+		t.Errorf("replay = %v, want %v", err, tokenErr.ErrInvalidRequestObject)
+	}
+
+	// the jti is scoped to its client
+	if err := authenticator.ConsumeRequestObject(ctx, "other", "jti-1", expiresAt); err != nil {
+		t.Errorf("other client: %v", err)
+	}
+
+	// an assertion with the same jti doesn't collide with the request object
+	if err := replay.Store(ctx, "client:jti-1", expiresAt); err != nil {
+		t.Errorf("assertion key: %v", err)
+	}
+
+	// the key outlives exp by the tolerated clock skew
+	if got := replay[requestObjectReplayPrefix+"client:jti-1"]; !got.After(expiresAt) {
+		t.Errorf("stored expiry %v is not after %v", got, expiresAt)
+	}
+}
+
+func TestFetchRequestObject(t *testing.T) {
+	ctx := context.Background()
+	requestURI := "https://client.example/request.jwt"
+	channel := entity.Channel{ClientId: "client", RequestURIs: []string{requestURI}}
+
+	tests := []struct {
+		name       string
+		requestURI string
+		body       string
+		wantErr    bool
+	}{
+		{name: "registered uri", requestURI: requestURI, body: "signed.request.object"},
+		{name: "at the size limit", requestURI: requestURI, body: strings.Repeat("a", requestObjectMaxSize)},
+		// refused rather than truncated into a different request object
+		{name: "over the size limit", requestURI: requestURI, body: strings.Repeat("a", requestObjectMaxSize+1), wantErr: true},
+		{name: "unregistered uri", requestURI: "https://attacker.example/request.jwt", body: "signed.request.object", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			resolver := NewKeyResolver()
+			resolver.httpClient = countingClient(tt.body, &requests)
+			authenticator := &clientAuthenticator{keys: resolver}
+
+			got, err := authenticator.FetchRequestObject(ctx, channel, tt.requestURI)
+			if tt.wantErr {
+				if !errors.Is(err, tokenErr.ErrInvalidRequestURI) {
+					t.Errorf("FetchRequestObject() = %v, want %v", err, tokenErr.ErrInvalidRequestURI)
+				}
+				return
+			}
+			if err != nil || got != tt.body {
+				t.Errorf("FetchRequestObject() = %d bytes, %v", len(got), err)
+			}
+		})
+	}
+}
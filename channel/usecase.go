@@ -14,53 +14,93 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
 	"github.com/umerthow/go-oauth/model"
 	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 const (
 	createChannelSuccessMessage = "Create Channel Successfully"
 	errorCreateChannelMessage   = "Create Channel Failed!"
 	updateChannelSuccessMessage = "Update Channel Successfully"
+	updateQuotaSuccessMessage   = "Update Channel Quota Successfully"
+	errorChannelNotFoundMessage = "Channel Not Found"
 )
 
 type Usecase interface {
 	CreateChannel(ctx context.Context, payload model.RequestChannel) response.Response
 	UpdateChannel(ctx context.Context, payload model.RequestChannel, channelID string) response.Response
+	UpdateQuota(ctx context.Context, clientId string, payload model.RequestQuota) response.Response
+	RegisterClient(ctx context.Context, payload model.ClientMetadata) response.Response
+	ReadClient(ctx context.Context, clientId, token string) response.Response
+	UpdateClient(ctx context.Context, clientId, token string, payload model.ClientInformation) response.Response
+	DeleteClient(ctx context.Context, clientId, token string) response.Response
+}
+
+// AuditRecorder write security events to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, event entity.AuditEvent)
 }
 
 type usecase struct {
-	serviceName       string
-	logger            *logrus.Logger
-	channelRepository ChannelsRepository
-	loc               *time.Location
+	serviceName        string
+	logger             *logrus.Logger
+	channelRepository  ChannelsRepository
+	loc                *time.Location
+	registrationURI    string
+	registrationScopes []string
+	auditRecorder      AuditRecorder
 }
 
 func NewChannelUsecase(property UsecaseChannelProperty) *usecase {
 	return &usecase{
-		serviceName:       property.ServiceName,
-		logger:            property.Logger,
-		channelRepository: property.ChannelsRepository,
-		loc:               property.Location,
+		serviceName:        property.ServiceName,
+		logger:             property.Logger,
+		channelRepository:  property.ChannelsRepository,
+		loc:                property.Location,
+		registrationURI:    property.RegistrationURI,
+		registrationScopes: property.RegistrationScopes,
+		auditRecorder:      property.AuditRecorder,
 	}
 }
 
 func (u *usecase) CreateChannel(ctx context.Context, payload model.RequestChannel) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.CreateChannel")
+	defer span.End()
+
 	now := time.Now().In(u.loc)
 
 	UserID := uuid.NewString()
 	channel := entity.Channel{
-		ID:          UserID,
-		Name:        payload.Name,
-		ClientId:    u.generateClientId(payload.Name),
-		SecretKey:   u.generateSecretKey(UserID),
-		IsActive:    true,
-		ClientType:  payload.ClientType,
-		GrantTypes:  payload.GrantTypes,
-		Scopes:      payload.Scopes,
-		RedirectURI: payload.RedirectURI,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:                                 UserID,
+		Name:                               payload.Name,
+		ClientId:                           u.generateClientId(payload.Name),
+		SecretKey:                          u.generateSecretKey(UserID),
+		IsActive:                           true,
+		ClientType:                         payload.ClientType,
+		GrantTypes:                         payload.GrantTypes,
+		Scopes:                             payload.Scopes,
+		RedirectURI:                        payload.RedirectURI,
+		RedirectURIs:                       payload.RedirectURIs,
+		ExchangePolicy:                     payload.ExchangePolicy,
+		TokenEndpointAuthMethod:            payload.TokenEndpointAuthMethod,
+		PublicKey:                          payload.PublicKey,
+		JwksURI:                            payload.JwksURI,
+		TLSClientAuthSubjectDN:             payload.TLSClientAuthSubjectDN,
+		TLSClientCertificateThumbprint:     payload.TLSClientCertificateThumbprint,
+		TLSClientCertificateBoundTokens:    payload.TLSClientCertificateBoundTokens,
+		DPoPBoundAccessTokens:              payload.DPoPBoundAccessTokens,
+		ConsentExempt:                      payload.ConsentExempt,
+		RequirePushedAuthorizationRequests: payload.RequirePushedAuthorizationRequests,
+		RequireSignedRequestObject:         payload.RequireSignedRequestObject,
+		RequestURIs:                        payload.RequestURIs,
+		PostLogoutRedirectURIs:             payload.PostLogoutRedirectURIs,
+		BackchannelLogoutURI:               payload.BackchannelLogoutURI,
+		Quota:                              entity.Quota(payload.Quota),
+		CreatedAt:                          now,
+		UpdatedAt:                          now,
 	}
 
 	if err := u.channelRepository.InsertOne(ctx, channel); err != nil {
@@ -69,11 +109,13 @@ func (u *usecase) CreateChannel(ctx context.Context, payload model.RequestChanne
 		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorCreateChannelMessage)
 	}
 
+	u.record(ctx, entity.AuditChannelCreated, "", channel.ClientId)
+
 	return response.NewSuccessResponse("", response.StatOK, createChannelSuccessMessage)
 }
 
 func (u *usecase) generateClientId(clientName string) string {
-	data := fmt.Sprintf("%s:%d", clientName, u.serviceName, time.Now().UnixNano())
+	data := fmt.Sprintf("%s:%s:%d", clientName, u.serviceName, time.Now().UnixNano())
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:8])
 }
@@ -88,6 +130,51 @@ func (u *usecase) generateSecretKey(userId string) string {
 }
 
 func (u *usecase) UpdateChannel(ctx context.Context, payload model.RequestChannel, channelId string) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.UpdateChannel")
+	defer span.End()
 
 	return response.NewSuccessResponse("", response.StatOK, updateChannelSuccessMessage)
 }
+
+// UpdateQuota replace the token quota of the channel
+func (u *usecase) UpdateQuota(ctx context.Context, clientId string, payload model.RequestQuota) response.Response {
+	ctx, span := tracing.Start(ctx, "channel.UpdateQuota")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, clientId)
+
+	channel, err := u.channelRepository.FindByClientId(ctx, clientId)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(err, http.StatusNotFound, nil, response.StatNotFound, errorChannelNotFoundMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	quota := entity.Quota(payload)
+	fields := bson.M{
+		"quota":      quota,
+		"updated_at": time.Now().In(u.loc),
+	}
+	if err := u.channelRepository.UpdateOne(ctx, channel.ID, fields); err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	u.record(ctx, entity.AuditChannelUpdated, "", channel.ClientId)
+
+	return response.NewSuccessResponse(quota, response.StatOK, updateQuotaSuccessMessage)
+}
+
+// record audit a change of the channel, an empty actor is taken from the request
+func (u *usecase) record(ctx context.Context, eventType entity.AuditEventType, actor, clientId string) {
+	if u.auditRecorder == nil {
+		return
+	}
+
+	u.auditRecorder.Record(ctx, entity.AuditEvent{
+		Type:     eventType,
+		Actor:    actor,
+		ClientId: clientId,
+		Outcome:  entity.AuditSuccess,
+	})
+}
@@ -1,10 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
-	"strconv"
 	"strings"
 	"time"
 
@@ -12,75 +12,236 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// FileEnv variable naming the YAML or JSON file the configuration is read from
+const FileEnv = "CONFIG_FILE"
+
+// Config of the service. Values come from the defaults, then the file named
+// by CONFIG_FILE when set, then the environment variables named by the env tags.
+// Secrets may instead be read from the file named by their *_FILE variable,
+// JWT_KEY_FILE for JWT_KEY, or from a registered SecretProvider.
 type Config struct {
 	Application struct {
-		Port           string
-		Name           string
-		AllowedOrigins []string
-		Location       *time.Location
-	}
+		Name    string `yaml:"name" env:"APP_NAME" validate:"required"`
+		Port    int    `yaml:"port" env:"PORT" validate:"required,min=1,max=65535"`
+		BaseURL string `yaml:"baseUrl" env:"BASE_URL" validate:"required,url"`
+		// Timezone IANA name of the location dates are computed in
+		Timezone string `yaml:"timezone" env:"TIMEZONE" validate:"required"`
+
+		Location *time.Location `yaml:"-"`
+	} `yaml:"application"`
+	CORS struct {
+		AllowedOrigins   []string `yaml:"allowedOrigins" env:"ALLOWED_ORIGINS" validate:"required,min=1"`
+		AllowedMethods   []string `yaml:"allowedMethods" env:"CORS_ALLOWED_METHODS" validate:"required,min=1,dive,oneof=GET HEAD POST PUT PATCH DELETE OPTIONS"`
+		AllowedHeaders   []string `yaml:"allowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+		ExposedHeaders   []string `yaml:"exposedHeaders" env:"CORS_EXPOSED_HEADERS"`
+		AllowCredentials bool     `yaml:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+	} `yaml:"cors"`
 	Logger struct {
-		Formatter logrus.Formatter
-	}
+		Formatter logrus.Formatter `yaml:"-"`
+	} `yaml:"-"`
 	Mongodb struct {
-		ClientOptions *options.ClientOptions
-		Database      string
-	}
+		URL             Secret        `yaml:"url" env:"MONGODB_URL,secret" validate:"required,startswith=mongodb"`
+		Database        string        `yaml:"database" env:"MONGODB_DATABASE" validate:"required"`
+		MinPoolSize     uint64        `yaml:"minPoolSize" env:"MONGODB_MIN_POOL_SIZE"`
+		MaxPoolSize     uint64        `yaml:"maxPoolSize" env:"MONGODB_MAX_POOL_SIZE" validate:"gtefield=MinPoolSize"`
+		MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime" env:"MONGODB_MAX_IDLE_CONNECTION_TIME_MS,unit=1ms" validate:"min=0"`
+		Collections     Collections   `yaml:"collections"`
+
+		ClientOptions *options.ClientOptions `yaml:"-"`
+	} `yaml:"mongodb"`
 	BasicAuth struct {
-		Username string
-		Password string
-	}
+		Username string `yaml:"username" env:"BASIC_AUTH_USERNAME" validate:"required"`
+		Password Secret `yaml:"password" env:"BASIC_AUTH_PASSWORD,secret" validate:"required"`
+	} `yaml:"basicAuth"`
 	JWT struct {
-		PrivateKey string
+		PrivateKey Secret `yaml:"privateKey" env:"JWT_KEY,secret" validate:"required,min=32"`
+		// Issuer iss claim of the issued tokens
+		Issuer string `yaml:"issuer" env:"JWT_ISSUER" validate:"required,url"`
+		// AccessTokenTTL lifetime of an access token
+		AccessTokenTTL time.Duration `yaml:"accessTokenTTL" env:"JWT_ACCESS_TOKEN_TTL_SECONDS,unit=1s" validate:"min=1s,max=24h"`
+		// RefreshTokenTTL lifetime of a refresh token, rotation keeps the original expiry
+		RefreshTokenTTL time.Duration `yaml:"refreshTokenTTL" env:"JWT_REFRESH_TOKEN_TTL_SECONDS,unit=1s" validate:"gtefield=AccessTokenTTL"`
+	} `yaml:"jwt"`
+	DPoP struct {
+		// NonceRequired force clients to include a server nonce in their proofs
+		NonceRequired bool `yaml:"nonceRequired" env:"DPOP_NONCE_REQUIRED"`
+	} `yaml:"dpop"`
+	Registration struct {
+		// InitialAccessTokens bearer tokens allowed to register clients, none disables registration
+		InitialAccessTokens []string `yaml:"initialAccessTokens" env:"REGISTRATION_INITIAL_ACCESS_TOKENS"`
+		// Scopes a dynamically registered client can ask for
+		Scopes []string `yaml:"scopes" env:"REGISTRATION_SCOPES"`
+	} `yaml:"registration"`
+	RateLimit struct {
+		// ClientPerMinute token endpoint requests of a client id
+		ClientPerMinute int `yaml:"clientPerMinute" env:"RATE_LIMIT_CLIENT_PER_MINUTE" validate:"min=1"`
+		// AddressPerMinute token endpoint requests of an address
+		AddressPerMinute int `yaml:"addressPerMinute" env:"RATE_LIMIT_ADDRESS_PER_MINUTE" validate:"min=1"`
+		// LockoutThreshold failed client authentications before a client is locked out from an address
+		LockoutThreshold int `yaml:"lockoutThreshold" env:"RATE_LIMIT_LOCKOUT_THRESHOLD" validate:"min=1"`
+		// Backend where lockouts are kept, mongodb shares them across replicas, memory keeps them in the process
+		Backend string `yaml:"backend" env:"RATE_LIMIT_BACKEND" validate:"oneof=mongodb memory"`
+	} `yaml:"rateLimit"`
+	Audit struct {
+		// Retention how long audit events are kept before a TTL index removes them, zero keeps them forever
+		Retention time.Duration `yaml:"retention" env:"AUDIT_RETENTION_DAYS,unit=24h" validate:"min=0"`
+	} `yaml:"audit"`
+	AccessLog struct {
+		// Redact query parameters and headers whose values are masked, on top of the ones never logged
+		Redact []string `yaml:"redact" env:"ACCESS_LOG_REDACT"`
+		// TrustedProxies IPs or CIDRs of the proxies whose X-Forwarded-For is
+		// believed, for the access log, the rate limit and the audit events
+		TrustedProxies []string `yaml:"trustedProxies" env:"TRUSTED_PROXIES" validate:"dive,ip|cidr"`
+	} `yaml:"accessLog"`
+	Health struct {
+		// Timeout time a readiness check has to succeed
+		Timeout time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT_SECONDS,unit=1s" validate:"min=1ms"`
+	} `yaml:"health"`
+	Server struct {
+		ReadTimeout  time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT_SECONDS,unit=1s" validate:"min=1s"`
+		WriteTimeout time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT_SECONDS,unit=1s" validate:"min=1s"`
+		IdleTimeout  time.Duration `yaml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT_SECONDS,unit=1s" validate:"min=1s"`
+		// DrainDelay time the service reports not ready before it stops listening
+		DrainDelay time.Duration `yaml:"drainDelay" env:"SERVER_DRAIN_DELAY_SECONDS,unit=1s" validate:"min=0"`
+		// ShutdownTimeout time in-flight requests and background workers have to finish on shutdown
+		ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT_SECONDS,unit=1s" validate:"min=1s"`
+	} `yaml:"server"`
+	Tracing struct {
+		// Exporter where spans are written, stdout or memory, none disables tracing
+		Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" validate:"oneof=none stdout memory"`
+	} `yaml:"tracing"`
+	TLS struct {
+		CertFile string `yaml:"certFile" env:"TLS_CERT_FILE" validate:"required_with=KeyFile"`
+		KeyFile  string `yaml:"keyFile" env:"TLS_KEY_FILE" validate:"required_with=CertFile"`
+		// ClientAuth request a client certificate for mutual TLS client authentication
+		ClientAuth bool `yaml:"clientAuth" env:"TLS_CLIENT_AUTH"`
+		// ClientCAFile trusted roots of tls_client_auth certificates
+		ClientCAFile string `yaml:"clientCAFile" env:"TLS_CLIENT_CA_FILE"`
+	} `yaml:"tls"`
+}
+
+// Collections names of the MongoDB collections
+type Collections struct {
+	Channel             string `yaml:"channel" env:"MONGODB_COLLECTION_CHANNEL" validate:"required"`
+	JTI                 string `yaml:"jti" env:"MONGODB_COLLECTION_JTI" validate:"required"`
+	User                string `yaml:"user" env:"MONGODB_COLLECTION_USER" validate:"required"`
+	Session             string `yaml:"session" env:"MONGODB_COLLECTION_SESSION" validate:"required"`
+	Consent             string `yaml:"consent" env:"MONGODB_COLLECTION_CONSENT" validate:"required"`
+	DeviceCode          string `yaml:"deviceCode" env:"MONGODB_COLLECTION_DEVICE_CODE" validate:"required"`
+	AuthorizationCode   string `yaml:"authorizationCode" env:"MONGODB_COLLECTION_AUTHORIZATION_CODE" validate:"required"`
+	PushedAuthorization string `yaml:"pushedAuthorization" env:"MONGODB_COLLECTION_PUSHED_AUTHORIZATION" validate:"required"`
+	RefreshToken        string `yaml:"refreshToken" env:"MONGODB_COLLECTION_REFRESH_TOKEN" validate:"required"`
+	RateLimitLockout    string `yaml:"rateLimitLockout" env:"MONGODB_COLLECTION_RATE_LIMIT_LOCKOUT" validate:"required"`
+	Usage               string `yaml:"usage" env:"MONGODB_COLLECTION_USAGE" validate:"required"`
+	Audit               string `yaml:"audit" env:"MONGODB_COLLECTION_AUDIT" validate:"required"`
+}
+
+// Renames configured name of every collection, keyed by the name the repositories use
+func (c Collections) Renames() map[string]string {
+	return map[string]string{
+		"oauth_channel":              c.Channel,
+		"oauth_jti":                  c.JTI,
+		"oauth_user":                 c.User,
+		"oauth_session":              c.Session,
+		"oauth_consent":              c.Consent,
+		"oauth_device_code":          c.DeviceCode,
+		"oauth_authorization_code":   c.AuthorizationCode,
+		"oauth_pushed_authorization": c.PushedAuthorization,
+		"oauth_refresh_token":        c.RefreshToken,
+		"oauth_rate_limit_lockout":   c.RateLimitLockout,
+		"oauth_usage":                c.Usage,
+		"audit_log":                  c.Audit,
 	}
 }
 
-func Load() *Config {
-	cfg := new(Config)
-	cfg.app()
-	cfg.basicAuth()
+// Load read, validate and complete the configuration. Every invalid value is
+// reported in the returned error, not only the first one.
+func Load() (*Config, error) {
+	cfg := defaults()
+
+	if file := os.Getenv(FileEnv); file != "" {
+		if err := cfg.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	errs := cfg.readEnv(os.LookupEnv, secretProviders(os.LookupEnv))
+	errs = append(errs, cfg.validate()...)
+
+	loc, err := time.LoadLocation(cfg.Application.Timezone)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("application.timezone: unknown location %q", cfg.Application.Timezone))
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+
+	cfg.Application.BaseURL = strings.TrimRight(cfg.Application.BaseURL, "/")
+	cfg.Application.Location = loc
 	cfg.logFormatter()
 	cfg.mongodb()
-	cfg.privateKey()
 
-	return cfg
+	return cfg, nil
 }
 
-func (cfg *Config) app() {
-	timezone := os.Getenv("TIMEZONE")
-	loc, _ := time.LoadLocation("Asia/Jakarta") // default timezone
-	appName := os.Getenv("APP_NAME")
-	port := os.Getenv("PORT")
+// defaults configuration of the values that have one
+func defaults() *Config {
+	cfg := new(Config)
+	cfg.Application.Timezone = "Asia/Jakarta"
+
+	cfg.CORS.AllowedOrigins = []string{"*"}
+	cfg.CORS.AllowedMethods = []string{"POST", "GET", "PUT", "DELETE"}
+	cfg.CORS.AllowedHeaders = []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization", "X-Request-ID"}
+	cfg.CORS.ExposedHeaders = []string{"X-Request-ID"}
+	cfg.CORS.AllowCredentials = true
 
-	if l, err := time.LoadLocation(timezone); err == nil {
-		loc = l
+	cfg.Mongodb.MaxPoolSize = 100
+	cfg.Mongodb.Collections = Collections{
+		Channel:             "oauth_channel",
+		JTI:                 "oauth_jti",
+		User:                "oauth_user",
+		Session:             "oauth_session",
+		Consent:             "oauth_consent",
+		DeviceCode:          "oauth_device_code",
+		AuthorizationCode:   "oauth_authorization_code",
+		PushedAuthorization: "oauth_pushed_authorization",
+		RefreshToken:        "oauth_refresh_token",
+		RateLimitLockout:    "oauth_rate_limit_lockout",
+		Usage:               "oauth_usage",
+		Audit:               "audit_log",
 	}
-	rawAllowedOrigins := strings.Trim(os.Getenv("ALLOWED_ORIGINS"), " ")
 
-	allowedOrigins := make([]string, 0)
-	if rawAllowedOrigins == "" {
-		allowedOrigins = append(allowedOrigins, "*")
-	} else {
-		allowedOrigins = strings.Split(rawAllowedOrigins, ",")
+	cfg.JWT.Issuer = "https://oauth.github.com"
+	cfg.JWT.AccessTokenTTL = time.Minute * 5
+	cfg.JWT.RefreshTokenTTL = time.Hour * 24 * 30
+
+	cfg.Registration.Scopes = []string{"openid", "profile", "email"}
+
+	cfg.RateLimit.ClientPerMinute = 60
+	cfg.RateLimit.AddressPerMinute = 120
+	cfg.RateLimit.LockoutThreshold = 5
+	cfg.RateLimit.Backend = "mongodb"
+
+	cfg.Audit.Retention = time.Hour * 24 * 90
+
+	cfg.AccessLog.Redact = []string{
+		"password", "code", "refresh_token", "refreshToken", "id_token_hint", "logout_token",
+		"client_assertion", "assertion", "subject_token", "actor_token", "request",
+		"Cookie", "DPoP",
 	}
 
-	cfg.Application.Port = port
-	cfg.Application.Name = appName
-	cfg.Application.AllowedOrigins = allowedOrigins
-	cfg.Application.Location = loc
-}
+	cfg.Health.Timeout = time.Second * 2
 
-func (cfg *Config) basicAuth() {
-	username := os.Getenv("BASIC_AUTH_USERNAME")
-	password := os.Getenv("BASIC_AUTH_PASSWORD")
+	cfg.Server.ReadTimeout = time.Second * 30
+	cfg.Server.WriteTimeout = time.Second * 60
+	cfg.Server.IdleTimeout = time.Second * 60
+	cfg.Server.DrainDelay = time.Second * 5
+	cfg.Server.ShutdownTimeout = time.Second * 30
 
-	cfg.BasicAuth.Username = username
-	cfg.BasicAuth.Password = password
-}
+	cfg.Tracing.Exporter = "none"
 
-func (cfg *Config) privateKey() {
-	privateKey := os.Getenv("JWT_KEY")
-	cfg.JWT.PrivateKey = privateKey
+	return cfg
 }
 
 func (cfg *Config) logFormatter() {
@@ -99,19 +260,12 @@ func (cfg *Config) logFormatter() {
 }
 
 func (cfg *Config) mongodb() {
-	appName := os.Getenv("APP_NAME")
-	uri := os.Getenv("MONGODB_URL")
-	db := os.Getenv("MONGODB_DATABASE")
-	minPoolSize, _ := strconv.ParseUint(os.Getenv("MONGODB_MIN_POOL_SIZE"), 10, 64)
-	maxPoolSize, _ := strconv.ParseUint(os.Getenv("MONGODB_MAX_POOL_SIZE"), 10, 64)
-	maxConnIdleTime, _ := strconv.ParseInt(os.Getenv("MONGODB_MAX_IDLE_CONNECTION_TIME_MS"), 10, 64)
 	opts := options.Client().
-		ApplyURI(uri).
-		SetAppName(appName).
-		SetMinPoolSize(minPoolSize).
-		SetMaxPoolSize(maxPoolSize).
-		SetMaxConnIdleTime(time.Millisecond * time.Duration(maxConnIdleTime))
+		ApplyURI(cfg.Mongodb.URL.Value()).
+		SetAppName(cfg.Application.Name).
+		SetMinPoolSize(cfg.Mongodb.MinPoolSize).
+		SetMaxPoolSize(cfg.Mongodb.MaxPoolSize).
+		SetMaxConnIdleTime(cfg.Mongodb.MaxConnIdleTime)
 
 	cfg.Mongodb.ClientOptions = opts
-	cfg.Mongodb.Database = db
 }
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// readEnv overlay the variables named by the env tags that are set. A
+// duration tagged with a unit also accepts a bare number of that unit, lists
+// are separated by commas or spaces. A setting tagged as secret is also read
+// from its *_FILE variable or the secret providers when the variable isn't set.
+func (cfg *Config) readEnv(lookup func(key string) (string, bool), secrets []SecretProvider) []error {
+	return readEnvStruct(reflect.ValueOf(cfg).Elem(), lookup, secrets)
+}
+
+func readEnvStruct(v reflect.Value, lookup func(key string) (string, bool), secrets []SecretProvider) (errs []error) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			if value.Kind() == reflect.Struct && field.Tag.Get("yaml") != "-" {
+				errs = append(errs, readEnvStruct(value, lookup, secrets)...)
+			}
+			continue
+		}
+
+		key, options, err := parseEnvTag(tag)
+		if err != nil {
+			panic(fmt.Sprintf("config: field %s: %v", field.Name, err))
+		}
+
+		raw, ok := lookup(key)
+		if options.secret {
+			if raw, ok, err = lookupSecret(key, lookup, secrets); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if !ok || strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		if err := setValue(value, strings.TrimSpace(raw), options.unit); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// envOptions of an env tag after the variable name
+type envOptions struct {
+	// unit of a bare number given for a duration
+	unit time.Duration
+	// secret the value may come from a *_FILE variable or a secret provider
+	secret bool
+}
+
+// parseEnvTag variable name and options of a tag such as "AUDIT_RETENTION_DAYS,unit=24h" or "JWT_KEY,secret"
+func parseEnvTag(tag string) (key string, options envOptions, err error) {
+	key, rest, _ := strings.Cut(tag, ",")
+	for _, option := range strings.Split(rest, ",") {
+		switch {
+		case option == "":
+		case option == "secret":
+			options.secret = true
+		case strings.HasPrefix(option, "unit="):
+			if options.unit, err = time.ParseDuration(strings.TrimPrefix(option, "unit=")); err != nil {
+				return key, options, err
+			}
+		default:
+			return key, options, fmt.Errorf("unknown env tag option %q", option)
+		}
+	}
+	return key, options, nil
+}
+
+func setValue(value reflect.Value, raw string, unit time.Duration) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := parseDuration(raw, unit)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		value.SetInt(n)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a positive integer", raw)
+		}
+		value.SetUint(n)
+	case reflect.Slice:
+		value.Set(reflect.ValueOf(strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})))
+	default:
+		panic(fmt.Sprintf("config: unsupported kind %s", value.Kind()))
+	}
+	return nil
+}
+
+// parseDuration a Go duration such as "90s", or a bare number of units
+func parseDuration(raw string, unit time.Duration) (time.Duration, error) {
+	if unit > 0 {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration", raw)
+	}
+	return duration, nil
+}
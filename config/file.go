@@ -0,0 +1,35 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile overlay the values of a YAML or JSON file, JSON is read as the YAML
+// subset it is. Durations are written as strings such as "30s" or "720h", keys
+// that don't match a setting are refused so typos don't go unnoticed.
+func (cfg *Config) readFile(file string) error {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .json", file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("config file %s: %w", file, err)
+	}
+
+	return nil
+}
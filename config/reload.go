@@ -0,0 +1,243 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadDebounce time file events are collected before reloading, editors write in several steps
+const reloadDebounce = time.Millisecond * 500
+
+// reloadable settings applied without a restart, other changes wait for the next one
+var reloadable = []string{"jwt.privateKey", "cors."}
+
+// Watcher reload the configuration when its file changes or the process
+// receives SIGHUP. A configuration that fails to load or validate is rejected
+// and the last good one is kept.
+type Watcher struct {
+	logger    *logrus.Logger
+	current   atomic.Pointer[Config]
+	mu        sync.Mutex
+	listeners []func(previous, next *Config)
+}
+
+// NewWatcher is a constructor, cfg is the configuration loaded at startup.
+func NewWatcher(logger *logrus.Logger, cfg *Config) *Watcher {
+	w := &Watcher{logger: logger}
+	w.current.Store(cfg)
+	return w
+}
+
+// Current last good configuration
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// OnReload call the listener with the previous and the next configuration on every reload that changed a reloadable setting
+func (w *Watcher) OnReload(listener func(previous, next *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, listener)
+}
+
+// Reload load the configuration again and hand it to the listeners when it is valid
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := Load()
+	if err != nil {
+		w.logger.WithError(err).Error("configuration reload rejected, keeping the last good configuration")
+		return err
+	}
+
+	previous := w.current.Load()
+	changes := Diff(previous, next)
+	if len(changes) == 0 {
+		w.logger.Info("configuration reloaded, nothing changed")
+		return nil
+	}
+
+	applied, pending := make([]string, 0), make([]string, 0)
+	for _, key := range changes {
+		if isReloadable(key) {
+			applied = append(applied, key)
+		} else {
+			pending = append(pending, key)
+		}
+	}
+
+	if len(pending) > 0 {
+		w.logger.WithField("pending", pending).Warn("configuration changes applied on the next restart")
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	// the other settings stay those the service was started with, Current
+	// must describe what is running
+	next = mergeReloadable(previous, next)
+	w.current.Store(next)
+	for _, listener := range w.listeners {
+		listener(previous, next)
+	}
+
+	w.logger.WithField("applied", applied).Info("configuration reloaded")
+	return nil
+}
+
+// Run reload on SIGHUP and on changes of the configuration file or the secret files until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	files := SecretFiles(os.LookupEnv)
+	if file := os.Getenv(FileEnv); file != "" {
+		files = append(files, file)
+	}
+	if len(files) > 0 {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			w.logger.Error(err)
+		} else {
+			defer watcher.Close()
+			// the directories are watched, files replaced by a rename or a symlink swap are seen too
+			for _, dir := range directories(files) {
+				if err := watcher.Add(dir); err != nil {
+					w.logger.Error(err)
+				}
+			}
+			events, errs = watcher.Events, watcher.Errors
+		}
+	}
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	var changed string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			w.logger.Info("SIGHUP received, reloading the configuration")
+			w.Reload()
+		case event := <-events:
+			if file, ok := watchedFile(files, event); ok {
+				changed = file
+				debounce.Reset(reloadDebounce)
+			}
+		case err := <-errs:
+			w.logger.Error(err)
+		case <-debounce.C:
+			w.logger.WithField("file", changed).Info("configuration file changed, reloading the configuration")
+			w.Reload()
+		}
+	}
+}
+
+// watchedFile file the event touches, or that lives in the data directory a mounted ConfigMap or Secret swaps
+func watchedFile(files []string, event fsnotify.Event) (string, bool) {
+	if event.Op == fsnotify.Chmod {
+		return "", false
+	}
+	name := filepath.Clean(event.Name)
+	for _, file := range files {
+		if name == filepath.Clean(file) {
+			return file, true
+		}
+		if filepath.Dir(name) == filepath.Dir(filepath.Clean(file)) && strings.HasPrefix(filepath.Base(name), "..data") {
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// directories distinct directories of the files
+func directories(files []string) []string {
+	dirs := make([]string, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		dir := filepath.Dir(filepath.Clean(file))
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func isReloadable(key string) bool {
+	for _, prefix := range reloadable {
+		if key == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(key, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeReloadable copy of the previous configuration with the reloadable settings of the next one
+func mergeReloadable(previous, next *Config) *Config {
+	merged := *previous
+	mergeStruct(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(next).Elem(), "")
+	return &merged
+}
+
+func mergeStruct(merged, next reflect.Value, prefix string) {
+	for i := 0; i < merged.NumField(); i++ {
+		name, _, _ := strings.Cut(merged.Type().Field(i).Tag.Get("yaml"), ",")
+		if name == "-" || name == "" {
+			continue
+		}
+
+		key := prefix + name
+		if merged.Field(i).Kind() == reflect.Struct {
+			mergeStruct(merged.Field(i), next.Field(i), key+".")
+			continue
+		}
+
+		if isReloadable(key) {
+			merged.Field(i).Set(next.Field(i))
+		}
+	}
+}
+
+// Diff keys of the settings whose values differ, named as in the configuration file
+func Diff(previous, next *Config) []string {
+	return diffStruct(reflect.ValueOf(previous).Elem(), reflect.ValueOf(next).Elem(), "")
+}
+
+func diffStruct(previous, next reflect.Value, prefix string) (changes []string) {
+	for i := 0; i < previous.NumField(); i++ {
+		name, _, _ := strings.Cut(previous.Type().Field(i).Tag.Get("yaml"), ",")
+		if name == "-" || name == "" {
+			continue
+		}
+
+		key := prefix + name
+		if previous.Field(i).Kind() == reflect.Struct {
+			changes = append(changes, diffStruct(previous.Field(i), next.Field(i), key+".")...)
+			continue
+		}
+
+		if !reflect.DeepEqual(previous.Field(i).Interface(), next.Field(i).Interface()) {
+			changes = append(changes, key)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func setTestEnv(t *testing.T, env map[string]string) {
+	t.Setenv(FileEnv, "")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func TestReloadAppliesOnlyReloadableSettings(t *testing.T) {
+	env := validEnv()
+	setTestEnv(t, env)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger, _ := test.NewNullLogger()
+	watcher := NewWatcher(logger, cfg)
+	var reloads []*Config
+	watcher.OnReload(func(previous, next *Config) {
+		reloads = append(reloads, next)
+	})
+
+	rotated := strings.Repeat("r", 32)
+	setTestEnv(t, map[string]string{
+		"JWT_KEY":         rotated,
+		"ALLOWED_ORIGINS": "https://app.example.com",
+		"PORT":            "9090",
+		"MONGODB_URL":     "mongodb://other:27017",
+	})
+	if err := watcher.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	current := watcher.Current()
+	if current.JWT.PrivateKey.Value() != rotated || strings.Join(current.CORS.AllowedOrigins, ",") != "https://app.example.com" {
+		t.Errorf("reloadable settings not applied: key changed %v, origins %v", current.JWT.PrivateKey.Value() == rotated, current.CORS.AllowedOrigins)
+	}
+	// the server still listens on the port and talks to the database it started with
+	if current.Application.Port != 8080 || current.Mongodb.URL.Value() != env["MONGODB_URL"] {
+		t.Errorf("settings applied on restart only changed: port %d, mongodb url changed %v", current.Application.Port, current.Mongodb.URL.Value() != env["MONGODB_URL"])
+	}
+	if len(reloads) != 1 || reloads[0] != current {
+		t.Errorf("listeners called %d times, want once with the current configuration", len(reloads))
+	}
+
+	// a change of settings applied on restart only doesn't call the listeners
+	setTestEnv(t, map[string]string{"PORT": "9191"})
+	if err := watcher.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloads) != 1 || watcher.Current() != current {
+		t.Errorf("reload of a port change called the listeners %d times, want none", len(reloads)-1)
+	}
+}
+
+func TestReloadRejectsInvalidConfiguration(t *testing.T) {
+	setTestEnv(t, validEnv())
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger, _ := test.NewNullLogger()
+	watcher := NewWatcher(logger, cfg)
+
+	setTestEnv(t, map[string]string{"JWT_KEY": "short"})
+	if err := watcher.Reload(); err == nil {
+		t.Fatal("invalid configuration accepted")
+	}
+	if watcher.Current() != cfg {
+		t.Error("last good configuration replaced")
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SecretFileSuffix of the variable naming the file a secret is read from, JWT_KEY_FILE for JWT_KEY
+const SecretFileSuffix = "_FILE"
+
+// redacted printed in place of a secret
+const redacted = "[REDACTED]"
+
+// Secret setting that must never end up in a log, an error or a dump. It
+// prints as [REDACTED], Value returns the actual secret.
+type Secret string
+
+// Value the secret itself, only hand it to what needs it
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String implement fmt.Stringer, %v and %s print [REDACTED]
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString implement fmt.GoStringer, %#v prints [REDACTED]
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalText implement encoding.TextMarshaler, JSON and YAML encoders write [REDACTED]
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// SecretProvider backend the settings tagged as secret are looked up in when
+// their variable isn't set, such as mounted files or a vault. The key is the
+// variable name of the env tag, ok is false when the backend doesn't hold it.
+type SecretProvider interface {
+	Secret(key string) (value string, ok bool, err error)
+}
+
+// SecretProviderFunc adapt a function to a SecretProvider
+type SecretProviderFunc func(key string) (string, bool, error)
+
+// Secret implement SecretProvider
+func (f SecretProviderFunc) Secret(key string) (string, bool, error) {
+	return f(key)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   []SecretProvider
+)
+
+// RegisterSecretProvider add a backend consulted after the environment and the
+// *_FILE variables, in the order they are registered. Register before Load.
+func RegisterSecretProvider(provider SecretProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers = append(providers, provider)
+}
+
+// FileSecrets read the secret from the file named by the key's *_FILE
+// variable, as mounted by Kubernetes or Docker secrets. The trailing line
+// break most editors and tools leave is dropped.
+func FileSecrets(lookup func(key string) (string, bool)) SecretProvider {
+	return SecretProviderFunc(func(key string) (string, bool, error) {
+		file, ok := lookup(key + SecretFileSuffix)
+		if !ok || strings.TrimSpace(file) == "" {
+			return "", false, nil
+		}
+
+		data, err := os.ReadFile(strings.TrimSpace(file))
+		if err != nil {
+			// the error names the file, never its content
+			return "", false, fmt.Errorf("%s%s: %w", key, SecretFileSuffix, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	})
+}
+
+// secretProviders the *_FILE variables first, then the registered backends
+func secretProviders(lookup func(key string) (string, bool)) []SecretProvider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return append([]SecretProvider{FileSecrets(lookup)}, providers...)
+}
+
+// lookupSecret the variable itself, or the first provider holding the key. A
+// variable set along with its *_FILE variant is ambiguous and refused.
+func lookupSecret(key string, lookup func(key string) (string, bool), secrets []SecretProvider) (string, bool, error) {
+	raw, ok := lookup(key)
+	if ok && strings.TrimSpace(raw) != "" {
+		if file, fileOk := lookup(key + SecretFileSuffix); fileOk && strings.TrimSpace(file) != "" {
+			return "", false, fmt.Errorf("set either %s or %s%s, not both", key, key, SecretFileSuffix)
+		}
+		return raw, true, nil
+	}
+
+	for _, provider := range secrets {
+		value, ok, err := provider.Secret(key)
+		if err != nil || ok {
+			return value, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+// SecretFiles files named by the *_FILE variables that are set, watched to
+// reload when a mounted secret is rotated
+func SecretFiles(lookup func(key string) (string, bool)) []string {
+	return secretFiles(reflect.TypeOf(Config{}), lookup)
+}
+
+func secretFiles(t reflect.Type, lookup func(key string) (string, bool)) (files []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && field.Tag.Get("yaml") != "-" {
+				files = append(files, secretFiles(field.Type, lookup)...)
+			}
+			continue
+		}
+
+		key, options, _ := parseEnvTag(tag)
+		if !options.secret {
+			continue
+		}
+		if file, ok := lookup(key + SecretFileSuffix); ok && strings.TrimSpace(file) != "" {
+			files = append(files, strings.TrimSpace(file))
+		}
+	}
+	return files
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecretFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestSecretFromFile(t *testing.T) {
+	key := strings.Repeat("f", 32)
+	env := validEnv()
+	delete(env, "JWT_KEY")
+	env["JWT_KEY_FILE"] = writeSecretFile(t, key+"\n")
+
+	cfg, errs := readTestEnv(env)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if cfg.JWT.PrivateKey.Value() != key {
+		t.Error("key not read from the file or its line break kept")
+	}
+	if files := SecretFiles(mapLookup(env)); len(files) != 1 || files[0] != env["JWT_KEY_FILE"] {
+		t.Errorf("watched secret files %v", files)
+	}
+}
+
+func TestSecretAndFileConflict(t *testing.T) {
+	env := validEnv()
+	env["JWT_KEY_FILE"] = writeSecretFile(t, strings.Repeat("f", 32))
+
+	// neither is taken, the key is then missing too
+	cfg, errs := readTestEnv(env)
+	if len(errs) == 0 || errs[0].Error() != "set either JWT_KEY or JWT_KEY_FILE, not both" {
+		t.Errorf("errors %v, want the conflict first", errs)
+	}
+	if cfg.JWT.PrivateKey != "" {
+		t.Error("key taken from one of the conflicting variables")
+	}
+
+	// an empty variable isn't set
+	env["JWT_KEY"] = " "
+	if _, errs := readTestEnv(env); len(errs) > 0 {
+		t.Errorf("empty JWT_KEY with JWT_KEY_FILE refused: %v", errs)
+	}
+}
+
+func TestSecretFileErrorNeverPrintsTheSecret(t *testing.T) {
+	env := validEnv()
+	delete(env, "BASIC_AUTH_PASSWORD")
+	env["BASIC_AUTH_PASSWORD_FILE"] = filepath.Join(t.TempDir(), "missing")
+
+	_, errs := readTestEnv(env)
+	if len(errs) == 0 || !strings.HasPrefix(errs[0].Error(), "BASIC_AUTH_PASSWORD_FILE: ") {
+		t.Errorf("errors %v, want the missing file named", errs)
+	}
+}
+
+func TestSecretNeverPrinted(t *testing.T) {
+	secret := Secret("admin-password")
+	for _, format := range []string{"%v", "%s", "%#v", "%+v"} {
+		if got := fmt.Sprintf(format, secret); got != redacted {
+			t.Errorf("%s prints %q", format, got)
+		}
+	}
+	if got, _ := secret.MarshalText(); string(got) != redacted {
+		t.Errorf("marshals to %q", got)
+	}
+}
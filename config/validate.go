@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate every rule of the validate tags, field errors are named after the file keys
+func (cfg *Config) validate() []error {
+	vld := validator.New()
+	vld.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	err := vld.Struct(cfg)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []error{err}
+	}
+
+	errs := make([]error, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		_, name, _ := strings.Cut(fieldErr.Namespace(), ".")
+		errs = append(errs, fmt.Errorf("%s: %s", name, describe(fieldErr)))
+	}
+	return errs
+}
+
+func describe(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return "is required with " + fieldErr.Param()
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at least " + fieldErr.Param() + " characters long"
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	case "gtefield":
+		return "must not be less than " + fieldErr.Param()
+	case "oneof":
+		return fmt.Sprintf("%v must be one of %s", fieldErr.Value(), fieldErr.Param())
+	case "url":
+		return fmt.Sprintf("%v is not a URL", fieldErr.Value())
+	case "ip|cidr":
+		return fmt.Sprintf("%v is neither an IP nor a CIDR", fieldErr.Value())
+	default:
+		return fmt.Sprintf("%v fails %s", fieldErr.Value(), fieldErr.Tag())
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// env variables of a valid configuration on top of the defaults
+func validEnv() map[string]string {
+	return map[string]string{
+		"APP_NAME":            "go-oauth",
+		"PORT":                "8080",
+		"BASE_URL":            "https://oauth.example.com",
+		"MONGODB_URL":         "mongodb://localhost:27017",
+		"MONGODB_DATABASE":    "oauth",
+		"BASIC_AUTH_USERNAME": "admin",
+		"BASIC_AUTH_PASSWORD": "admin-password",
+		"JWT_KEY":             strings.Repeat("k", 32),
+	}
+}
+
+func mapLookup(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+// readTestEnv errors of reading then validating the variables over the defaults
+func readTestEnv(env map[string]string) (*Config, []error) {
+	cfg := defaults()
+	lookup := mapLookup(env)
+	errs := cfg.readEnv(lookup, []SecretProvider{FileSecrets(lookup)})
+	return cfg, append(errs, cfg.validate()...)
+}
+
+func TestValidConfiguration(t *testing.T) {
+	if _, errs := readTestEnv(validEnv()); len(errs) > 0 {
+		t.Fatalf("valid configuration refused: %v", errs)
+	}
+}
+
+func TestValidationReportsEveryError(t *testing.T) {
+	env := validEnv()
+	delete(env, "APP_NAME")
+	env["PORT"] = "70000"
+	env["BASE_URL"] = "not a url"
+	env["JWT_KEY"] = "short"
+	env["JWT_ACCESS_TOKEN_TTL_SECONDS"] = "600"
+	env["JWT_REFRESH_TOKEN_TTL_SECONDS"] = "60"
+	env["RATE_LIMIT_BACKEND"] = "redis"
+	env["TRUSTED_PROXIES"] = "10.0.0.0/8,proxy.local"
+	env["TLS_CERT_FILE"] = "/etc/tls/cert.pem"
+	env["SERVER_READ_TIMEOUT_SECONDS"] = "thirty"
+
+	_, errs := readTestEnv(env)
+
+	want := []string{
+		"SERVER_READ_TIMEOUT_SECONDS: \"thirty\" is not a duration",
+		"application.name: is required",
+		"application.port: must be at most 65535",
+		"application.baseUrl: not a url is not a URL",
+		"jwt.privateKey: must be at least 32 characters long",
+		"jwt.refreshTokenTTL: must not be less than AccessTokenTTL",
+		"rateLimit.backend: redis must be one of mongodb memory",
+		"accessLog.trustedProxies[1]: proxy.local is neither an IP nor a CIDR",
+		"tls.keyFile: is required with CertFile",
+	}
+	got := make([]string, len(errs))
+	for i, err := range errs {
+		got[i] = err.Error()
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidationNeverPrintsSecrets(t *testing.T) {
+	env := validEnv()
+	env["JWT_KEY"] = "short-secret"
+
+	_, errs := readTestEnv(env)
+	if len(errs) != 1 {
+		t.Fatalf("errors %v, want the key length only", errs)
+	}
+	if strings.Contains(errs[0].Error(), "short-secret") {
+		t.Errorf("error %q prints the secret", errs[0])
+	}
+}
@@ -0,0 +1,79 @@
+package consent
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/middleware"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	errorMissingSubjectMessage    = "Access Token Was Not Issued For A User Login"
+	errorInsufficientScopeMessage = "Access Token Has No consents Scope"
+)
+
+type HTTPHandler struct {
+	Logger  *logrus.Logger
+	Usecase Usecase
+}
+
+func NewConsentHTTPHandler(logger *logrus.Logger, router *mux.Router, bearerAuth middleware.RouteMiddleware, usecase Usecase) {
+	handler := &HTTPHandler{
+		Logger:  logger,
+		Usecase: usecase,
+	}
+
+	router.HandleFunc("/go-oauth/v1/consents", bearerAuth.Verify(handler.ListConsents)).Methods(http.MethodGet)
+	router.HandleFunc("/go-oauth/v1/consents/{clientId}", bearerAuth.Verify(handler.RevokeConsent)).Methods(http.MethodDelete)
+}
+
+func (handler *HTTPHandler) ListConsents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := subject(w, r)
+	if !ok {
+		return
+	}
+
+	resp := handler.Usecase.ListConsents(ctx, userID)
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := subject(w, r)
+	if !ok {
+		return
+	}
+
+	resp := handler.Usecase.RevokeConsent(ctx, userID, mux.Vars(r)["clientId"])
+	response.JSON(w, resp)
+}
+
+// subject user whose consents the access token manages. The token must carry
+// the consents scope, any token of the user would otherwise let a client read
+// and drop the grants of every other client.
+func subject(w http.ResponseWriter, r *http.Request) (string, bool) {
+	ctx := r.Context()
+
+	userID := entity.GetSubjectFromContext(ctx)
+	if userID == "" {
+		resp := response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, errorMissingSubjectMessage)
+		response.JSON(w, resp)
+		return "", false
+	}
+
+	if !entity.HasScope(ctx, entity.ScopeConsents) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+entity.ScopeConsents+`"`)
+		resp := response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, errorInsufficientScopeMessage)
+		response.JSON(w, resp)
+		return "", false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,16 @@
+package consent
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/channel"
+)
+
+type UsecaseConsentProperty struct {
+	ServiceName        string
+	Logger             *logrus.Logger
+	Location           *time.Location
+	ConsentsRepository ConsentsRepository
+	ChannelsRepository channel.ChannelsRepository
+}
@@ -0,0 +1,125 @@
+package consent
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ConsentsRepository interface {
+	FindOne(ctx context.Context, userID, clientId string) (consent entity.Consent, err error)
+	FindByUserID(ctx context.Context, userID string) (consents []entity.Consent, err error)
+	// Grant add the scopes to the consent of the user for the channel, creating it when missing
+	Grant(ctx context.Context, userID, clientId string, scopes []string, now time.Time) (err error)
+	DeleteOne(ctx context.Context, userID, clientId string) (err error)
+	// EnsureIndexes keep a single consent per user and channel
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type consentRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewConsentRepository(logger *logrus.Logger, db mongodb.Database) ConsentsRepository {
+	col := db.Collection("oauth_consent")
+	return &consentRepository{logger, col}
+}
+
+func (r *consentRepository) FindOne(ctx context.Context, userID, clientId string) (consent entity.Consent, err error) {
+	filter := bson.M{
+		"user_id":   userID,
+		"client_id": clientId,
+	}
+
+	if err = r.col.FindOne(ctx, filter).Decode(&consent); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *consentRepository) FindByUserID(ctx context.Context, userID string) (consents []entity.Consent, err error) {
+	cursor, err := r.col.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+	defer cursor.Close(ctx)
+
+	consents = []entity.Consent{}
+	for cursor.Next(ctx) {
+		var consent entity.Consent
+		if err = cursor.Decode(&consent); err != nil {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		consents = append(consents, consent)
+	}
+
+	return
+}
+
+func (r *consentRepository) Grant(ctx context.Context, userID, clientId string, scopes []string, now time.Time) (err error) {
+	filter := bson.M{
+		"user_id":   userID,
+		"client_id": clientId,
+	}
+	update := bson.M{
+		"$addToSet": bson.M{"scopes": bson.M{"$each": scopes}},
+		"$set":      bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{
+			"id":         uuid.NewString(),
+			"created_at": now,
+		},
+	}
+
+	_, err = r.col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent grant inserted the consent first, the retry updates it
+		_, err = r.col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *consentRepository) DeleteOne(ctx context.Context, userID, clientId string) (err error) {
+	resp, err := r.col.DeleteOne(ctx, bson.M{"user_id": userID, "client_id": clientId})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *consentRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.UniqueIndex("user_id", "client_id")); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
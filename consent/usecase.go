@@ -0,0 +1,96 @@
+package consent
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+)
+
+const (
+	listConsentSuccessMessage   = "Get Consents Successfully"
+	revokeConsentSuccessMessage = "Revoke Consent Successfully"
+	errorConsentNotFoundMessage = "Consent Not Found"
+)
+
+type Usecase interface {
+	ListConsents(ctx context.Context, userID string) response.Response
+	RevokeConsent(ctx context.Context, userID, clientId string) response.Response
+}
+
+type usecase struct {
+	serviceName       string
+	logger            *logrus.Logger
+	consentRepository ConsentsRepository
+	channelRepository channel.ChannelsRepository
+	loc               *time.Location
+}
+
+func NewConsentUsecase(property UsecaseConsentProperty) *usecase {
+	return &usecase{
+		serviceName:       property.ServiceName,
+		logger:            property.Logger,
+		consentRepository: property.ConsentsRepository,
+		channelRepository: property.ChannelsRepository,
+		loc:               property.Location,
+	}
+}
+
+// ListConsents the channels the user granted access to, with the consented scopes
+func (u *usecase) ListConsents(ctx context.Context, userID string) response.Response {
+	ctx, span := tracing.Start(ctx, "consent.ListConsents")
+	defer span.End()
+
+	consents, err := u.consentRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	data := make([]model.ConsentResponse, 0, len(consents))
+	for _, consent := range consents {
+		item := model.ConsentResponse{
+			ClientId:  consent.ClientId,
+			Scopes:    make([]model.ConsentScope, 0, len(consent.Scopes)),
+			CreatedAt: consent.CreatedAt,
+			UpdatedAt: consent.UpdatedAt,
+		}
+
+		// a deleted channel still lists its consent so it can be revoked
+		channel, err := u.channelRepository.FindByClientId(ctx, consent.ClientId)
+		if err == nil {
+			item.ChannelName = channel.Name
+		} else if err != exception.ErrNotFound {
+			return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		}
+
+		for _, scope := range consent.Scopes {
+			item.Scopes = append(item.Scopes, model.ConsentScope{Scope: scope, Description: entity.DescribeScope(scope)})
+		}
+
+		data = append(data, item)
+	}
+
+	return response.NewSuccessResponse(data, response.StatOK, listConsentSuccessMessage)
+}
+
+// RevokeConsent remove the consent, the next authorization asks the user again
+func (u *usecase) RevokeConsent(ctx context.Context, userID, clientId string) response.Response {
+	ctx, span := tracing.Start(ctx, "consent.RevokeConsent")
+	defer span.End()
+
+	if err := u.consentRepository.DeleteOne(ctx, userID, clientId); err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(err, http.StatusNotFound, nil, response.StatNotFound, errorConsentNotFoundMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	return response.NewSuccessResponse("", response.StatOK, revokeConsentSuccessMessage)
+}
@@ -0,0 +1,141 @@
+package device
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/middleware"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/user"
+)
+
+const (
+	verificationApprovedMessage = "Your device has been activated, you can return to it now."
+	verificationDeniedMessage   = "The device request has been denied."
+	verificationInvalidMessage  = "The code is invalid or has already been used."
+	verificationExpiredMessage  = "The code has expired, please request a new one on your device."
+	verificationErrorMessage    = "Something went wrong, please try again."
+)
+
+type HTTPHandler struct {
+	Logger   *logrus.Logger
+	Validate *validator.Validate
+	Usecase  Usecase
+	Sessions *user.SessionManager
+}
+
+func NewDeviceHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, middleware middleware.RouteMiddleware, rateLimit middleware.RouteMiddleware, usecase Usecase, sessions *user.SessionManager) {
+	handler := &HTTPHandler{
+		Logger:   logger,
+		Validate: validate,
+		Usecase:  usecase,
+		Sessions: sessions,
+	}
+
+	router.HandleFunc("/go-oauth/v1/device_authorization", rateLimit.Verify(middleware.Verify(handler.DeviceAuthorization))).Methods(http.MethodPost)
+	router.HandleFunc("/go-oauth/v1/device", handler.VerificationPage).Methods(http.MethodGet)
+	router.HandleFunc("/go-oauth/v1/device", handler.Verification).Methods(http.MethodPost)
+}
+
+func (handler *HTTPHandler) DeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.DeviceAuthorizationRequest
+	ctx := r.Context()
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp = response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.DeviceAuthorization(ctx, payload)
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) VerificationPage(w http.ResponseWriter, r *http.Request) {
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		http.Redirect(w, r, user.LoginURL(r.URL.RequestURI()), http.StatusFound)
+		return
+	}
+
+	page := verificationPage{
+		UserCode:  r.URL.Query().Get("user_code"),
+		CSRFToken: session.CSRFToken,
+	}
+
+	handler.render(w, http.StatusOK, page)
+}
+
+func (handler *HTTPHandler) Verification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		http.Redirect(w, r, user.LoginURL(r.URL.Path), http.StatusFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil || !user.ValidCSRF(session, r.PostForm.Get("csrf_token")) {
+		handler.render(w, http.StatusBadRequest, verificationPage{CSRFToken: session.CSRFToken, Message: verificationErrorMessage, IsError: true})
+		return
+	}
+
+	payload := model.DeviceVerification{
+		UserCode: r.PostForm.Get("user_code"),
+		Approved: r.PostForm.Get("action") == "approve",
+		UserID:   session.UserID,
+		AuthTime: session.AuthTime,
+		AMR:      session.AMR,
+	}
+
+	err = handler.Usecase.Verify(ctx, payload)
+	switch err {
+	case nil:
+		message := verificationDeniedMessage
+		if payload.Approved {
+			message = verificationApprovedMessage
+		}
+		handler.render(w, http.StatusOK, verificationPage{CSRFToken: session.CSRFToken, Message: message})
+	case tokenErr.ErrInvalidUserCode:
+		handler.render(w, http.StatusBadRequest, verificationPage{UserCode: payload.UserCode, CSRFToken: session.CSRFToken, Message: verificationInvalidMessage, IsError: true})
+	case tokenErr.ErrExpiredToken:
+		handler.render(w, http.StatusBadRequest, verificationPage{CSRFToken: session.CSRFToken, Message: verificationExpiredMessage, IsError: true})
+	default:
+		handler.render(w, http.StatusInternalServerError, verificationPage{UserCode: payload.UserCode, CSRFToken: session.CSRFToken, Message: verificationErrorMessage, IsError: true})
+	}
+}
+
+func (handler *HTTPHandler) render(w http.ResponseWriter, statusCode int, page verificationPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := verificationTemplate.Execute(w, page); err != nil {
+		handler.Logger.Error(err)
+	}
+}
+
+func (handler *HTTPHandler) validateRequestBody(body interface{}) (err error) {
+	err = handler.Validate.Struct(body)
+	if err == nil {
+		return
+	}
+
+	errorFields := err.(validator.ValidationErrors)
+	errorField := errorFields[0]
+	err = fmt.Errorf("invalid '%s' with value '%v'", errorField.Field(), errorField.Value())
+
+	return
+}
@@ -0,0 +1,17 @@
+package device
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/channel"
+)
+
+type UsecaseDeviceProperty struct {
+	ServiceName         string
+	Logger              *logrus.Logger
+	Location            *time.Location
+	ClientAuthenticator channel.ClientAuthenticator
+	DeviceRepository    DeviceRepository
+	VerificationURI     string
+}
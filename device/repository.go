@@ -0,0 +1,133 @@
+package device
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type DeviceRepository interface {
+	InsertOne(ctx context.Context, entryData entity.DeviceAuthorization) (err error)
+	FindByDeviceCode(ctx context.Context, deviceCode string) (device entity.DeviceAuthorization, err error)
+	FindByUserCode(ctx context.Context, userCode string) (device entity.DeviceAuthorization, err error)
+	// UpdateStatus return exception.ErrNotFound when the code is no longer pending, so it is approved or denied once
+	UpdateStatus(ctx context.Context, id string, status entity.DeviceAuthorizationStatus, userID string, authTime time.Time, amr []string, acr string, updatedAt time.Time) (err error)
+	UpdatePolling(ctx context.Context, id string, interval int, polledAt time.Time) (err error)
+	// DeleteOne return exception.ErrNotFound when the code was already deleted, so it is redeemed once
+	DeleteOne(ctx context.Context, id string) (err error)
+	// EnsureIndexes create the index removing the codes once they expire
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type deviceRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewDeviceRepository(logger *logrus.Logger, db mongodb.Database) DeviceRepository {
+	col := db.Collection("oauth_device_code")
+	return &deviceRepository{logger, col}
+}
+
+func (r *deviceRepository) InsertOne(ctx context.Context, entryData entity.DeviceAuthorization) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *deviceRepository) FindByDeviceCode(ctx context.Context, deviceCode string) (device entity.DeviceAuthorization, err error) {
+	return r.findOne(ctx, bson.M{"device_code": deviceCode})
+}
+
+func (r *deviceRepository) FindByUserCode(ctx context.Context, userCode string) (device entity.DeviceAuthorization, err error) {
+	return r.findOne(ctx, bson.M{"user_code": userCode})
+}
+
+func (r *deviceRepository) findOne(ctx context.Context, filter bson.M) (device entity.DeviceAuthorization, err error) {
+	if err = r.col.FindOne(ctx, filter).Decode(&device); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *deviceRepository) UpdateStatus(ctx context.Context, id string, status entity.DeviceAuthorizationStatus, userID string, authTime time.Time, amr []string, acr string, updatedAt time.Time) (err error) {
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"user_id":    userID,
+			"auth_time":  authTime,
+			"amr":        amr,
+			"acr":        acr,
+			"updated_at": updatedAt,
+		},
+	}
+
+	// the status is checked in the same write, a concurrent approval can't overwrite it
+	resp, err := r.col.UpdateOne(ctx, bson.M{"id": id, "status": entity.DeviceAuthorizationPending}, update)
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.MatchedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *deviceRepository) UpdatePolling(ctx context.Context, id string, interval int, polledAt time.Time) (err error) {
+	update := bson.M{
+		"$set": bson.M{
+			"interval":       interval,
+			"last_polled_at": polledAt,
+		},
+	}
+
+	return r.updateOne(ctx, id, update)
+}
+
+func (r *deviceRepository) updateOne(ctx context.Context, id string, update bson.M) (err error) {
+	if _, err = r.col.UpdateOne(ctx, bson.M{"id": id}, update); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *deviceRepository) DeleteOne(ctx context.Context, id string) (err error) {
+	resp, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *deviceRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.TTLIndex("expires_at", 0)); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
@@ -0,0 +1,30 @@
+package device
+
+import "html/template"
+
+type verificationPage struct {
+	UserCode  string
+	CSRFToken string
+	Message   string
+	IsError   bool
+}
+
+var verificationTemplate = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Device Activation</title>
+</head>
+<body>
+	<h1>Activate your device</h1>
+	{{if .Message}}<p{{if .IsError}} style="color:#c00"{{end}}>{{.Message}}</p>{{end}}
+	<form method="POST">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<label for="user_code">Enter the code shown on your device</label>
+		<input id="user_code" name="user_code" value="{{.UserCode}}" autocomplete="off" autofocus>
+		<button type="submit" name="action" value="approve">Approve</button>
+		<button type="submit" name="action" value="deny">Deny</button>
+	</form>
+</body>
+</html>
+`))
@@ -0,0 +1,213 @@
+package device
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+)
+
+const (
+	deviceAuthorizationSuccessMessage = "Device Authorization Successfully"
+	errorDeviceAuthorizationMessage   = "Device Authorization Failed!"
+	errorNotAllowDeviceMessage        = "Request Not Allow To Use Device Authorization"
+
+	// DeviceCodeExpiresIn lifetime of the device and user code
+	DeviceCodeExpiresIn = time.Minute * 10
+	// PollingInterval minimum seconds the device must wait between token requests
+	PollingInterval = 5
+
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+)
+
+type Usecase interface {
+	DeviceAuthorization(ctx context.Context, payload model.DeviceAuthorizationRequest) response.Response
+	Verify(ctx context.Context, payload model.DeviceVerification) (err error)
+}
+
+type usecase struct {
+	serviceName         string
+	logger              *logrus.Logger
+	clientAuthenticator channel.ClientAuthenticator
+	deviceRepository    DeviceRepository
+	loc                 *time.Location
+	verificationURI     string
+}
+
+func NewDeviceUsecase(property UsecaseDeviceProperty) *usecase {
+	return &usecase{
+		serviceName:         property.ServiceName,
+		logger:              property.Logger,
+		clientAuthenticator: property.ClientAuthenticator,
+		deviceRepository:    property.DeviceRepository,
+		loc:                 property.Location,
+		verificationURI:     property.VerificationURI,
+	}
+}
+
+func (u *usecase) DeviceAuthorization(ctx context.Context, payload model.DeviceAuthorizationRequest) response.Response {
+	ctx, span := tracing.Start(ctx, "device.DeviceAuthorization")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, payload.ClientId)
+
+	now := time.Now().In(u.loc)
+
+	channel, err := u.clientAuthenticator.Authenticate(ctx, payload.ClientAuthentication)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, err.Error())
+		}
+		if err == tokenErr.ErrInvalidClient {
+			entity.SetInvalidClientInContext(ctx)
+			return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorDeviceAuthorizationMessage)
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	if !channel.IsActive || !channel.HasGrantType(entity.DeviceCode) {
+		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorNotAllowDeviceMessage)
+	}
+
+	scopes := channel.Scopes
+	if payload.Scope != "" {
+		scopes = strings.Fields(payload.Scope)
+	}
+
+	if !channel.HasScopes(scopes) || !channel.AllowsOpenID(scopes) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, tokenErr.ErrInvalidScope.Error())
+	}
+
+	deviceCode, err := generateDeviceCode()
+	if err != nil {
+		u.logger.WithContext(ctx).Error(err)
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorDeviceAuthorizationMessage)
+	}
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		u.logger.WithContext(ctx).Error(err)
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorDeviceAuthorizationMessage)
+	}
+
+	device := entity.DeviceAuthorization{
+		ID:         uuid.NewString(),
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientId:   channel.ClientId,
+		XDeviceId:  entity.GetDeviceIdFromContext(ctx),
+		Scopes:     scopes,
+		Status:     entity.DeviceAuthorizationPending,
+		Interval:   PollingInterval,
+		ExpiresAt:  now.Add(DeviceCodeExpiresIn),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := u.deviceRepository.InsertOne(ctx, device); err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorDeviceAuthorizationMessage)
+	}
+
+	data := model.DeviceAuthorizationResponse{
+		DeviceCode:              device.DeviceCode,
+		UserCode:                device.UserCode,
+		VerificationURI:         u.verificationURI,
+		VerificationURIComplete: u.verificationURI + "?user_code=" + url.QueryEscape(device.UserCode),
+		ExpiresIn:               int64(DeviceCodeExpiresIn.Seconds()),
+		Interval:                device.Interval,
+	}
+
+	return response.NewSuccessResponse(data, response.StatOK, deviceAuthorizationSuccessMessage)
+}
+
+// Verify approve or deny, on behalf of the signed in user, the pending device
+// authorization identified by the user code
+func (u *usecase) Verify(ctx context.Context, payload model.DeviceVerification) (err error) {
+	ctx, span := tracing.Start(ctx, "device.Verify")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	device, err := u.deviceRepository.FindByUserCode(ctx, NormalizeUserCode(payload.UserCode))
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return tokenErr.ErrInvalidUserCode
+		}
+		return
+	}
+
+	if device.IsExpired(now) {
+		return tokenErr.ErrExpiredToken
+	}
+
+	if device.Status != entity.DeviceAuthorizationPending {
+		return tokenErr.ErrInvalidUserCode
+	}
+
+	status := entity.DeviceAuthorizationDenied
+	if payload.Approved {
+		status = entity.DeviceAuthorizationApproved
+	}
+
+	err = u.deviceRepository.UpdateStatus(ctx, device.ID, status, payload.UserID, payload.AuthTime, payload.AMR, entity.ACR(payload.AMR), now)
+	if err == exception.ErrNotFound {
+		// approved or denied in the meantime
+		return tokenErr.ErrInvalidUserCode
+	}
+	return
+}
+
+// NormalizeUserCode uppercase the user input and restore the XXXX-XXXX format
+func NormalizeUserCode(userCode string) string {
+	code := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+
+	if len(code) != userCodeLength {
+		return code
+	}
+
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+func generateDeviceCode() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func generateUserCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(userCodeCharset)))
+
+	for i := 0; i < userCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(userCodeCharset[n.Int64()])
+	}
+
+	return NormalizeUserCode(sb.String()), nil
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEventType security events kept in the audit log
+type AuditEventType string
+
+// define audit event types
+const (
+	AuditChannelCreated   AuditEventType = "channel.created"
+	AuditChannelUpdated   AuditEventType = "channel.updated"
+	AuditChannelDeleted   AuditEventType = "channel.deleted"
+	AuditTokenIssued      AuditEventType = "token.issued"
+	AuditTokenRefused     AuditEventType = "token.refused"
+	AuditTokenRevoked     AuditEventType = "token.revoked"
+	AuditAdminLoginFailed AuditEventType = "admin.login_failed"
+)
+
+// audit event outcomes
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent who did what to which channel, from where and how it ended
+type AuditEvent struct {
+	ID        string            `json:"id" bson:"id"`
+	Type      AuditEventType    `json:"type" bson:"type"`
+	Actor     string            `json:"actor" bson:"actor"`
+	ClientId  string            `json:"client_id" bson:"client_id"`
+	IP        string            `json:"ip" bson:"ip"`
+	DeviceID  string            `json:"device_id" bson:"device_id"`
+	RequestID string            `json:"request_id" bson:"request_id"`
+	Outcome   string            `json:"outcome" bson:"outcome"`
+	Reason    string            `json:"reason,omitempty" bson:"reason,omitempty"`
+	Details   map[string]string `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"`
+}
+
+// ActorContextKey who is authenticated on the request, the admin or the subject of a token
+type ActorContextKey struct{}
+
+// RemoteAddressContextKey address of the peer of the request
+type RemoteAddressContextKey struct{}
+
+// GetActorFromContext actor of the request, empty when anonymous
+func GetActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(ActorContextKey{}).(string)
+	return actor
+}
+
+// GetRemoteAddressFromContext address of the peer, empty outside of a request
+func GetRemoteAddressFromContext(ctx context.Context) string {
+	address, _ := ctx.Value(RemoteAddressContextKey{}).(string)
+	return address
+}
+
+// LookupDeviceIdFromContext device id of the request, empty when the route doesn't require one
+func LookupDeviceIdFromContext(ctx context.Context) string {
+	deviceID, _ := ctx.Value(DeviceContextKey{}).(string)
+	return deviceID
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// PKCE code challenge methods (RFC 7636)
+const (
+	CodeChallengePlain = "plain"
+	CodeChallengeS256  = "S256"
+)
+
+// Authorization code issued by the authorize endpoint, redeemed once at the token endpoint
+type Authorization struct {
+	ID                  string    `json:"id" bson:"id"`
+	Code                string    `json:"code" bson:"code"`
+	ClientId            string    `json:"client_id" bson:"client_id"`
+	UserID              string    `json:"user_id" bson:"user_id"`
+	SessionID           string    `json:"session_id" bson:"session_id"`
+	RedirectURI         string    `json:"redirect_uri" bson:"redirect_uri"`
+	Scopes              []string  `json:"scopes" bson:"scopes"`
+	Nonce               string    `json:"nonce" bson:"nonce"`
+	AuthTime            time.Time `json:"auth_time" bson:"auth_time"`
+	AMR                 []string  `json:"amr" bson:"amr"`
+	Acr                 string    `json:"acr" bson:"acr"`
+	CodeChallenge       string    `json:"code_challenge" bson:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method" bson:"code_challenge_method"`
+	ExpiresAt           time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedAt           time.Time `json:"created_at" bson:"created_at"`
+}
+
+// IsExpired report whether the code can no longer be redeemed
+func (a *Authorization) IsExpired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
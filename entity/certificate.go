@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+)
+
+type CertificateContextKey struct{}
+
+// CertificateIntermediatesContextKey intermediate certificates the client sent after its own
+type CertificateIntermediatesContextKey struct{}
+
+// Confirmation proof-of-possession key bound to a token (RFC 7800)
+type Confirmation struct {
+	// X5tS256 thumbprint of the client certificate (RFC 8705 section 3.1)
+	X5tS256 string `json:"x5t#S256,omitempty"`
+	// JKT thumbprint of the DPoP proof key (RFC 9449 section 6)
+	JKT string `json:"jkt,omitempty"`
+}
+
+// CertificateThumbprint base64url encoded SHA-256 of the DER certificate
+func CertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// GetCertificateFromContext client certificate of the TLS connection, nil when none was presented
+func GetCertificateFromContext(ctx context.Context) *x509.Certificate {
+	cert, _ := ctx.Value(CertificateContextKey{}).(*x509.Certificate)
+
+	return cert
+}
+
+// GetCertificateIntermediatesFromContext intermediate CA certificates of the TLS client certificate chain
+func GetCertificateIntermediatesFromContext(ctx context.Context) []*x509.Certificate {
+	intermediates, _ := ctx.Value(CertificateIntermediatesContextKey{}).([]*x509.Certificate)
+
+	return intermediates
+}
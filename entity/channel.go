@@ -12,8 +12,58 @@ type GrantType string
 const (
 	AuthorizationCode GrantType = "authorization_code"
 	ClientCredentials GrantType = "client_credentials"
+	DeviceCode        GrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	TokenExchange     GrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	JWTBearer         GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	// Refreshing channels with this grant type get a refresh token with interactive logins
+	Refreshing GrantType = "refresh_token"
 )
 
+// AuthMethod client authentication method at the token endpoint
+type AuthMethod string
+
+// define client authentication method
+const (
+	ClientSecretPost AuthMethod = "client_secret_post"
+	PrivateKeyJWT    AuthMethod = "private_key_jwt"
+	// TLSClientAuth certificate issued by a trusted CA with the registered subject DN
+	TLSClientAuth AuthMethod = "tls_client_auth"
+	// SelfSignedTLSClientAuth certificate matching the registered thumbprint
+	SelfSignedTLSClientAuth AuthMethod = "self_signed_tls_client_auth"
+)
+
+// ClientAssertionJWTBearer assertion type of a private_key_jwt client assertion
+const ClientAssertionJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ExchangePolicy restrict what a channel can obtain through token exchange
+type ExchangePolicy struct {
+	Audiences []string `json:"audiences" bson:"audiences"`
+	Scopes    []string `json:"scopes" bson:"scopes"`
+}
+
+// AllowsAudience report whether the audience can be requested
+func (p *ExchangePolicy) AllowsAudience(audience string) bool {
+	return contains(p.Audiences, audience)
+}
+
+// AllowsScopes report whether every scope can be requested
+func (p *ExchangePolicy) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(p.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Quota limits on the tokens issued to a channel, zero means unlimited
+type Quota struct {
+	Daily   int64 `json:"daily" bson:"daily"`
+	Monthly int64 `json:"monthly" bson:"monthly"`
+	// AlertPercent share of a limit that raises a warning once reached, zero disables it
+	AlertPercent int `json:"alert_percent" bson:"alert_percent"`
+}
+
 type Channel struct {
 	ID          string      `json:"id" bson:"id"`
 	Name        string      `json:"name" bson:"name"`
@@ -24,8 +74,103 @@ type Channel struct {
 	GrantTypes  []GrantType `json:"grant_types" bson:"grant_types"`
 	Scopes      []string    `json:"scopes" bson:"scopes"`
 	RedirectURI string      `json:"redirect_uri" bson:"redirect_uri"`
-	CreatedAt   time.Time   `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at" bson:"updated_at"`
+	// RedirectURIs additional redirect uris accepted by the authorize endpoint
+	RedirectURIs []string `json:"redirect_uris" bson:"redirect_uris"`
+	// ExchangePolicy only used by the token exchange grant
+	ExchangePolicy ExchangePolicy `json:"exchange_policy" bson:"exchange_policy"`
+	// TokenEndpointAuthMethod empty means client_secret_post
+	TokenEndpointAuthMethod AuthMethod `json:"token_endpoint_auth_method" bson:"token_endpoint_auth_method"`
+	// PublicKey PEM encoded key, JwksURI is used when no key is registered
+	PublicKey string `json:"public_key" bson:"public_key"`
+	JwksURI   string `json:"jwks_uri" bson:"jwks_uri"`
+	// mutual TLS client authentication (RFC 8705)
+	TLSClientAuthSubjectDN          string `json:"tls_client_auth_subject_dn" bson:"tls_client_auth_subject_dn"`
+	TLSClientCertificateThumbprint  string `json:"tls_client_certificate_thumbprint" bson:"tls_client_certificate_thumbprint"`
+	TLSClientCertificateBoundTokens bool   `json:"tls_client_certificate_bound_access_tokens" bson:"tls_client_certificate_bound_access_tokens"`
+	// ConsentExempt first-party channel the user is never asked to consent to
+	ConsentExempt bool `json:"consent_exempt" bson:"consent_exempt"`
+	// RequirePushedAuthorizationRequests only accept authorization requests pushed to the PAR endpoint
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests" bson:"require_pushed_authorization_requests"`
+	// RequireSignedRequestObject only accept authorization requests passed as a signed request object (RFC 9101)
+	RequireSignedRequestObject bool `json:"require_signed_request_object" bson:"require_signed_request_object"`
+	// RequestURIs https uris request objects can be fetched from
+	RequestURIs []string `json:"request_uris" bson:"request_uris"`
+	// PostLogoutRedirectURIs uris the end session endpoint may send the user back to
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris" bson:"post_logout_redirect_uris"`
+	// BackchannelLogoutURI notified with a logout token when a session the channel took part in ends
+	BackchannelLogoutURI string `json:"backchannel_logout_uri" bson:"backchannel_logout_uri"`
+	// RegistrationAccessTokenHash SHA-256 of the token managing a dynamically registered channel
+	RegistrationAccessTokenHash string `json:"-" bson:"registration_access_token_hash"`
+	// Quota tokens the channel can be issued, counted for billing
+	Quota Quota `json:"quota" bson:"quota"`
+	// DPoPBoundAccessTokens require a DPoP proof on every token request (RFC 9449)
+	DPoPBoundAccessTokens bool      `json:"dpop_bound_access_tokens" bson:"dpop_bound_access_tokens"`
+	CreatedAt             time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// HasGrantType report whether the channel is allowed to use the grant type
+func (c *Channel) HasGrantType(grantType GrantType) bool {
+	for _, gt := range c.GrantTypes {
+		if gt == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthMethod client authentication method, defaults to client_secret_post
+func (c *Channel) AuthMethod() AuthMethod {
+	if c.TokenEndpointAuthMethod == "" {
+		return ClientSecretPost
+	}
+	return c.TokenEndpointAuthMethod
+}
+
+// HasRedirectURI report whether the uri exactly matches a registered redirect uri
+func (c *Channel) HasRedirectURI(uri string) bool {
+	return uri != "" && (uri == c.RedirectURI || contains(c.RedirectURIs, uri))
+}
+
+// HasRequestURI report whether the request objects can be fetched from the uri
+func (c *Channel) HasRequestURI(uri string) bool {
+	return uri != "" && contains(c.RequestURIs, uri)
+}
+
+// HasPostLogoutRedirectURI report whether the uri exactly matches a registered post logout redirect uri
+func (c *Channel) HasPostLogoutRedirectURI(uri string) bool {
+	return uri != "" && contains(c.PostLogoutRedirectURIs, uri)
+}
+
+// AllRedirectURIs every registered redirect uri
+func (c *Channel) AllRedirectURIs() []string {
+	uris := make([]string, 0, len(c.RedirectURIs)+1)
+	if c.RedirectURI != "" {
+		uris = append(uris, c.RedirectURI)
+	}
+	for _, uri := range c.RedirectURIs {
+		if uri != c.RedirectURI {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// HasScopes report whether every requested scope is registered on the channel
+func (c *Channel) HasScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsOpenID report whether the channel can be granted the openid scope. ID
+// tokens and logout tokens are signed with the client secret, a channel
+// authenticating otherwise has no key to verify them with.
+func (c *Channel) AllowsOpenID(scopes []string) bool {
+	return c.SecretKey != "" || !contains(scopes, ScopeOpenID)
 }
 
 type Client struct {
@@ -39,6 +184,15 @@ func (c *Client) GetID() string {
 	return c.ID
 }
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func GetDeviceIdFromContext(ctx context.Context) string {
 	deviceID := ctx.Value(DeviceContextKey{})
 
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// ScopeConsents scope a token needs to list and revoke the consents of its subject
+const ScopeConsents = "consents"
+
+// ScopeDescriptions text shown on the consent screen, unknown scopes show their name
+var ScopeDescriptions = map[string]string{
+	ScopeOpenID:   "Sign you in with your account",
+	ScopeProfile:  "Read your name and basic profile",
+	ScopeEmail:    "Read your email address",
+	ScopeConsents: "Review and revoke the access you granted to applications",
+}
+
+// Consent scopes a user allowed a channel to obtain on their behalf
+type Consent struct {
+	ID        string    `json:"id" bson:"id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	ClientId  string    `json:"client_id" bson:"client_id"`
+	Scopes    []string  `json:"scopes" bson:"scopes"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// Covers report whether every scope has been consented
+func (c *Consent) Covers(scopes []string) bool {
+	for _, scope := range scopes {
+		if !contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// DescribeScope human readable description of a scope
+func DescribeScope(scope string) string {
+	if description, ok := ScopeDescriptions[scope]; ok {
+		return description
+	}
+	return scope
+}
+
+type SubjectContextKey struct{}
+
+// GetSubjectFromContext end-user who logged in for the access token authenticated
+// on the request, empty when the token wasn't issued for a user login
+func GetSubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(SubjectContextKey{}).(string)
+
+	return subject
+}
+
+type ScopesContextKey struct{}
+
+// GetScopesFromContext scopes of the access token authenticated on the request
+func GetScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(ScopesContextKey{}).([]string)
+
+	return scopes
+}
+
+// HasScope report whether the access token authenticated on the request carries the scope
+func HasScope(ctx context.Context, scope string) bool {
+	return contains(GetScopesFromContext(ctx), scope)
+}
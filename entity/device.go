@@ -1,3 +1,40 @@
 package entity
 
+import "time"
+
 type DeviceContextKey struct{}
+
+// DeviceAuthorizationStatus state of a device authorization request
+type DeviceAuthorizationStatus string
+
+// define device authorization state
+const (
+	DeviceAuthorizationPending  DeviceAuthorizationStatus = "pending"
+	DeviceAuthorizationApproved DeviceAuthorizationStatus = "approved"
+	DeviceAuthorizationDenied   DeviceAuthorizationStatus = "denied"
+)
+
+// DeviceAuthorization pending authorization of an input-constrained device (RFC 8628)
+type DeviceAuthorization struct {
+	ID           string                    `json:"id" bson:"id"`
+	DeviceCode   string                    `json:"device_code" bson:"device_code"`
+	UserCode     string                    `json:"user_code" bson:"user_code"`
+	ClientId     string                    `json:"client_id" bson:"client_id"`
+	XDeviceId    string                    `json:"device_id" bson:"device_id"`
+	UserID       string                    `json:"user_id" bson:"user_id"`
+	AuthTime     time.Time                 `json:"auth_time" bson:"auth_time"`
+	AMR          []string                  `json:"amr" bson:"amr"`
+	Acr          string                    `json:"acr" bson:"acr"`
+	Scopes       []string                  `json:"scopes" bson:"scopes"`
+	Status       DeviceAuthorizationStatus `json:"status" bson:"status"`
+	Interval     int                       `json:"interval" bson:"interval"`
+	LastPolledAt time.Time                 `json:"last_polled_at" bson:"last_polled_at"`
+	ExpiresAt    time.Time                 `json:"expires_at" bson:"expires_at"`
+	CreatedAt    time.Time                 `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time                 `json:"updated_at" bson:"updated_at"`
+}
+
+// IsExpired report whether the device code can no longer be used
+func (d *DeviceAuthorization) IsExpired(now time.Time) bool {
+	return !now.Before(d.ExpiresAt)
+}
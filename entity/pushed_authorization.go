@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// RequestURIPrefix prefix of the request_uri returned by the PAR endpoint (RFC 9126 section 2.2)
+const RequestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+// PushedAuthorization authorization request parameters pushed by an authenticated channel
+type PushedAuthorization struct {
+	ID                  string `json:"id" bson:"id"`
+	RequestURI          string `json:"request_uri" bson:"request_uri"`
+	ClientId            string `json:"client_id" bson:"client_id"`
+	ResponseType        string `json:"response_type" bson:"response_type"`
+	RedirectURI         string `json:"redirect_uri" bson:"redirect_uri"`
+	Scope               string `json:"scope" bson:"scope"`
+	State               string `json:"state" bson:"state"`
+	Nonce               string `json:"nonce" bson:"nonce"`
+	CodeChallenge       string `json:"code_challenge" bson:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" bson:"code_challenge_method"`
+	// Signed the parameters were pushed as a signed request object
+	Signed    bool      `json:"signed" bson:"signed"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// IsExpired report whether the request_uri can no longer be used
+func (p *PushedAuthorization) IsExpired(now time.Time) bool {
+	return !now.Before(p.ExpiresAt)
+}
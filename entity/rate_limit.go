@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// RateLimit outcome of a rate limit check, the tightest bucket is reported
+type RateLimit struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter time to wait before the next attempt can succeed
+	RetryAfter time.Duration
+	// Locked the caller is locked out after repeated failed client authentications
+	Locked bool
+}
+
+// Lockout failed client authentications of a client from an address
+type Lockout struct {
+	Key       string    `json:"key" bson:"key"`
+	Failures  int       `json:"failures" bson:"failures"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// RefreshToken refresh token issued for an interactive login, only its hash is stored
+type RefreshToken struct {
+	ID        string    `json:"id" bson:"id"`
+	TokenHash string    `json:"-" bson:"token_hash"`
+	ClientId  string    `json:"client_id" bson:"client_id"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	SessionID string    `json:"session_id" bson:"session_id"`
+	Scopes    []string  `json:"scopes" bson:"scopes"`
+	AuthTime  time.Time `json:"auth_time" bson:"auth_time"`
+	AMR       []string  `json:"amr" bson:"amr"`
+	Acr       string    `json:"acr" bson:"acr"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// IsExpired report whether the refresh token can no longer be redeemed
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
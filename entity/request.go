@@ -0,0 +1,71 @@
+package entity
+
+import "context"
+
+// RequestContextKey what is known of the request being served
+type RequestContextKey struct{}
+
+// Request scope of the request being served, the layers handling it fill it
+// in as they learn about it so every log line can be correlated
+type Request struct {
+	ID       string
+	Route    string
+	ClientId string
+	// AuthenticatedClientId the channel whose authentication was verified,
+	// unlike ClientId it can't be any value sent by the caller
+	AuthenticatedClientId string
+	// InvalidClient the client authentication of the request was rejected
+	InvalidClient bool
+}
+
+// GetRequestFromContext scope of the request, nil outside of a request
+func GetRequestFromContext(ctx context.Context) *Request {
+	request, _ := ctx.Value(RequestContextKey{}).(*Request)
+	return request
+}
+
+// GetRequestIDFromContext identifier of the request, empty outside of a request
+func GetRequestIDFromContext(ctx context.Context) string {
+	if request := GetRequestFromContext(ctx); request != nil {
+		return request.ID
+	}
+	return ""
+}
+
+// SetClientIdInContext remember the channel the request is made for
+func SetClientIdInContext(ctx context.Context, clientId string) {
+	if request := GetRequestFromContext(ctx); request != nil && clientId != "" {
+		request.ClientId = clientId
+	}
+}
+
+// SetAuthenticatedClientIdInContext remember the channel whose authentication was verified
+func SetAuthenticatedClientIdInContext(ctx context.Context, clientId string) {
+	if request := GetRequestFromContext(ctx); request != nil {
+		request.AuthenticatedClientId = clientId
+	}
+}
+
+// GetAuthenticatedClientIdFromContext channel whose authentication was verified, empty when none was
+func GetAuthenticatedClientIdFromContext(ctx context.Context) string {
+	if request := GetRequestFromContext(ctx); request != nil {
+		return request.AuthenticatedClientId
+	}
+	return ""
+}
+
+// SetInvalidClientInContext remember the client authentication was rejected,
+// what the rate limit counts towards a lockout
+func SetInvalidClientInContext(ctx context.Context) {
+	if request := GetRequestFromContext(ctx); request != nil {
+		request.InvalidClient = true
+	}
+}
+
+// IsInvalidClientInContext report whether the client authentication of the request was rejected
+func IsInvalidClientInContext(ctx context.Context) bool {
+	if request := GetRequestFromContext(ctx); request != nil {
+		return request.InvalidClient
+	}
+	return false
+}
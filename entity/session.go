@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+)
+
+// authentication method references of the amr claim (RFC 8176)
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMRMFA      = "mfa"
+)
+
+// authentication context classes of the acr claim (OIDC Core section 2)
+const (
+	ACRPassword    = "urn:go-oauth:acr:password"
+	ACRMultiFactor = "urn:go-oauth:acr:mfa"
+)
+
+// ACR authentication context class the methods of a login satisfied, empty without a login
+func ACR(amr []string) string {
+	if len(amr) == 0 {
+		return ""
+	}
+	for _, method := range amr {
+		if method == AMRMFA {
+			return ACRMultiFactor
+		}
+	}
+	return ACRPassword
+}
+
+// Session browser session of an authenticated user
+type Session struct {
+	ID       string    `json:"id" bson:"id"`
+	UserID   string    `json:"user_id" bson:"user_id"`
+	AuthTime time.Time `json:"auth_time" bson:"auth_time"`
+	AMR      []string  `json:"amr" bson:"amr"`
+	// MFAPending password checked, the second factor is still expected
+	MFAPending bool   `json:"mfa_pending" bson:"mfa_pending"`
+	CSRFToken  string `json:"-" bson:"csrf_token"`
+	// ClientIds channels an authorization code was issued to during the session
+	ClientIds []string  `json:"client_ids" bson:"client_ids"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// SID identifier of the session given to channels in the sid claim (OIDC
+// Back-Channel Logout section 2.1). The id is the cookie value and never
+// leaves the server, the sid is derived from it.
+func SID(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(sessionID))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// IsExpired report whether the session can no longer be used
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
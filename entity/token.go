@@ -4,18 +4,44 @@ import (
 	"time"
 )
 
+// token type identifiers (RFC 8693 section 3)
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// Actor party acting on behalf of the token subject, nested actors are the delegation history
+type Actor struct {
+	Subject  string `json:"sub"`
+	ClientId string `json:"clientId,omitempty"`
+	Act      *Actor `json:"act,omitempty"`
+}
+
 type GenerateBasic struct {
-	ID         string      `json:"id"`
-	ClientId   string      `json:"clientId"`
-	ClientType string      `json:"clientType"`
-	IsActive   bool        `json:"isActive"`
-	IsPublic   bool        `json:"isPublic"`
-	GrantTypes []GrantType `json:"grantTypes"`
-	Scopes     []string    `json:"scopes"`
-	XDeviceId  string      `json:"deviceId"`
-	Domain     string      `json:"domain"`
-	CreateAt   time.Time   `json:"createdAt"`
-	TokenInfo  TokenInfo
+	ID         string        `json:"id"`
+	ClientId   string        `json:"clientId"`
+	ClientType string        `json:"clientType"`
+	IsActive   bool          `json:"isActive"`
+	IsPublic   bool          `json:"isPublic"`
+	GrantTypes []GrantType   `json:"grantTypes"`
+	Scopes     []string      `json:"scopes"`
+	XDeviceId  string        `json:"deviceId"`
+	Domain     string        `json:"domain"`
+	CreateAt   time.Time     `json:"createdAt"`
+	Act        *Actor        `json:"act,omitempty"`
+	Cnf        *Confirmation `json:"cnf,omitempty"`
+	// IssuedTokenType issued_token_type of the response, only set by token exchange
+	IssuedTokenType string `json:"-"`
+	// end-user authentication, an id_token is only issued when AuthTime is set
+	Nonce    string    `json:"nonce,omitempty"`
+	AuthTime time.Time `json:"authTime"`
+	Acr      string    `json:"acr,omitempty"`
+	AMR      []string  `json:"amr,omitempty"`
+	// SessionID browser session of the login, refresh tokens are revoked with it
+	SessionID string `json:"-"`
+	// Quota of the channel, checked when the token is issued
+	Quota     Quota `json:"-"`
+	TokenInfo TokenInfo
 }
 
 type TokenInfo struct {
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// usage counting periods
+const (
+	UsagePeriodDay   = "day"
+	UsagePeriodMonth = "month"
+)
+
+// Usage tokens issued to a channel during a day or a month
+type Usage struct {
+	ClientId  string    `json:"client_id" bson:"client_id"`
+	Period    string    `json:"period" bson:"period"`
+	Start     time.Time `json:"start" bson:"start"`
+	Count     int64     `json:"count" bson:"count"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
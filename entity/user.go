@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// User end-user (resource owner) authenticating on interactive grants
+type User struct {
+	ID                  string    `json:"id" bson:"id"`
+	Username            string    `json:"username" bson:"username"`
+	Email               string    `json:"email" bson:"email"`
+	EmailVerified       bool      `json:"email_verified" bson:"email_verified"`
+	PasswordHash        string    `json:"-" bson:"password_hash"`
+	Name                string    `json:"name" bson:"name"`
+	GivenName           string    `json:"given_name" bson:"given_name"`
+	FamilyName          string    `json:"family_name" bson:"family_name"`
+	IsActive            bool      `json:"is_active" bson:"is_active"`
+	IsLocked            bool      `json:"is_locked" bson:"is_locked"`
+	FailedLoginAttempts int       `json:"failed_login_attempts" bson:"failed_login_attempts"`
+	LockedUntil         time.Time `json:"locked_until" bson:"locked_until"`
+	// TOTPSecret base32 secret, pending until the first code confirms the enrolment
+	TOTPSecret   string `json:"-" bson:"totp_secret"`
+	TOTPEnabled  bool   `json:"totp_enabled" bson:"totp_enabled"`
+	TOTPLastStep int64  `json:"-" bson:"totp_last_step"`
+	// RecoveryCodes SHA-256 of the unused recovery codes
+	RecoveryCodes []string  `json:"-" bson:"recovery_codes"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// CanLogin report whether the account is enabled and not locked at the given time
+func (u *User) CanLogin(now time.Time) bool {
+	return u.IsActive && !u.IsLocked && !now.Before(u.LockedUntil)
+}
+
+// UserInfo standard claims of the user
+func (u *User) UserInfo() UserInfo {
+	return UserInfo{
+		Subject:           u.ID,
+		Name:              u.Name,
+		GivenName:         u.GivenName,
+		FamilyName:        u.FamilyName,
+		PreferredUsername: u.Username,
+		Email:             u.Email,
+		EmailVerified:     u.EmailVerified,
+		UpdatedAt:         u.UpdatedAt.Unix(),
+	}
+}
@@ -0,0 +1,56 @@
+package entity
+
+// OpenID Connect scopes controlling which claims are released (OIDC Core section 5.4)
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// UserInfo standard claims of an end-user (OIDC Core section 5.1)
+type UserInfo struct {
+	Subject           string
+	Name              string
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+	Picture           string
+	Locale            string
+	Zoneinfo          string
+	UpdatedAt         int64
+	Email             string
+	EmailVerified     bool
+}
+
+// Claims release the claims allowed by the granted scopes
+func (u *UserInfo) Claims(scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": u.Subject,
+	}
+
+	if contains(scopes, ScopeProfile) {
+		setClaim(claims, "name", u.Name)
+		setClaim(claims, "given_name", u.GivenName)
+		setClaim(claims, "family_name", u.FamilyName)
+		setClaim(claims, "preferred_username", u.PreferredUsername)
+		setClaim(claims, "picture", u.Picture)
+		setClaim(claims, "locale", u.Locale)
+		setClaim(claims, "zoneinfo", u.Zoneinfo)
+		if u.UpdatedAt != 0 {
+			claims["updated_at"] = u.UpdatedAt
+		}
+	}
+
+	if contains(scopes, ScopeEmail) && u.Email != "" {
+		claims["email"] = u.Email
+		claims["email_verified"] = u.EmailVerified
+	}
+
+	return claims
+}
+
+func setClaim(claims map[string]interface{}, name, value string) {
+	if value != "" {
+		claims[name] = value
+	}
+}
@@ -7,18 +7,44 @@ var New = errors.New
 
 // known errors
 var (
-	ErrInvalidRedirectURI   = errors.New("invalid redirect uri")
-	ErrInvalidAuthorizeCode = errors.New("invalid authorize code")
-	ErrInvalidAccessToken   = errors.New("invalid access token")
-	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
-	ErrExpiredAccessToken   = errors.New("expired access token")
-	ErrExpiredRefreshToken  = errors.New("expired refresh token")
-	ErrMissingCodeVerifier  = errors.New("missing code verifier")
-	ErrMissingCodeChallenge = errors.New("missing code challenge")
-	ErrInvalidCodeChallenge = errors.New("invalid code challenge")
-	ErrUnauthorizedClient   = errors.New("unauthorized_client")
-	ErrTokenExpired         = errors.New("token has expired")
-	ErrInvalidSignature     = errors.New("token has an invalid signature")
-	ErrTokenMalformed       = errors.New("token malformed")
-	ErrValidationIssuer     = errors.New("invalid token issuer")
+	ErrInvalidRedirectURI      = errors.New("invalid redirect uri")
+	ErrInvalidAuthorizeCode    = errors.New("invalid authorize code")
+	ErrInvalidAccessToken      = errors.New("invalid access token")
+	ErrInvalidRefreshToken     = errors.New("invalid refresh token")
+	ErrExpiredAccessToken      = errors.New("expired access token")
+	ErrExpiredRefreshToken     = errors.New("expired refresh token")
+	ErrMissingCodeVerifier     = errors.New("missing code verifier")
+	ErrMissingCodeChallenge    = errors.New("missing code challenge")
+	ErrInvalidCodeChallenge    = errors.New("invalid code challenge")
+	ErrUnauthorizedClient      = errors.New("unauthorized_client")
+	ErrTokenExpired            = errors.New("token has expired")
+	ErrInvalidSignature        = errors.New("token has an invalid signature")
+	ErrTokenMalformed          = errors.New("token malformed")
+	ErrValidationIssuer        = errors.New("invalid token issuer")
+	ErrInvalidGrant            = errors.New("invalid_grant")
+	ErrInvalidScope            = errors.New("invalid_scope")
+	ErrInvalidTarget           = errors.New("invalid_target")
+	ErrInvalidRequest          = errors.New("invalid_request")
+	ErrInvalidClient           = errors.New("invalid_client")
+	ErrInvalidAssertion        = errors.New("invalid assertion")
+	ErrReplayedAssertion       = errors.New("assertion has already been used")
+	ErrUnsupportedResponseType = errors.New("unsupported_response_type")
+	ErrServerError             = errors.New("server_error")
+	ErrLoginRequired           = errors.New("login_required")
+	ErrConsentRequired         = errors.New("consent_required")
+	ErrInvalidClientMetadata   = errors.New("invalid_client_metadata")
+	ErrInvalidRedirectURIs     = errors.New("invalid_redirect_uri")
+	ErrInvalidRequestURI       = errors.New("invalid_request_uri")
+	ErrPushedRequestRequired   = errors.New("pushed authorization request required")
+	ErrInvalidRequestObject    = errors.New("invalid_request_object")
+	ErrSignedRequestRequired   = errors.New("signed request object required")
+	ErrAuthorizationPending    = errors.New("authorization_pending")
+	ErrSlowDown                = errors.New("slow_down")
+	ErrAccessDenied            = errors.New("access_denied")
+	ErrExpiredToken            = errors.New("expired_token")
+	ErrInvalidUserCode         = errors.New("invalid user code")
+	ErrInvalidIDTokenHint      = errors.New("invalid id token hint")
+	ErrInvalidPostLogoutURI    = errors.New("invalid post logout redirect uri")
+	ErrQuotaExceeded           = errors.New("token quota exceeded")
+	ErrSigningKeyMissing       = errors.New("signing key not loaded")
 )
@@ -0,0 +1,39 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+type HTTPHandler struct {
+	Logger  *logrus.Logger
+	Usecase Usecase
+}
+
+func NewHealthHTTPHandler(logger *logrus.Logger, router *mux.Router, usecase Usecase) {
+	handler := &HTTPHandler{
+		Logger:  logger,
+		Usecase: usecase,
+	}
+
+	router.HandleFunc(LivenessPath, handler.Liveness).Methods(http.MethodGet)
+	router.HandleFunc(ReadinessPath, handler.Readiness).Methods(http.MethodGet)
+}
+
+func (handler *HTTPHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	resp := handler.Usecase.Liveness(r.Context())
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	resp := handler.Usecase.Readiness(r.Context())
+	response.JSON(w, resp)
+}
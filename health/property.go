@@ -0,0 +1,16 @@
+package health
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type UsecaseHealthProperty struct {
+	ServiceName string
+	Logger      *logrus.Logger
+	// Checks dependencies the service needs to be ready, all are checked concurrently
+	Checks []Check
+	// Timeout time a check has to succeed
+	Timeout time.Duration
+}
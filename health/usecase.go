@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	aliveMessage    = "Application is alive"
+	readyMessage    = "Application is ready"
+	notReadyMessage = "Application is not ready"
+
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+
+	// DefaultTimeout time a check has to succeed when none is configured
+	DefaultTimeout = time.Second * 2
+)
+
+// Checker dependency the service needs to serve requests
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapt a function to a Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Check named dependency reported by the readiness probe
+type Check struct {
+	Name    string
+	Checker Checker
+}
+
+type Usecase interface {
+	Liveness(ctx context.Context) response.Response
+	Readiness(ctx context.Context) response.Response
+	Drain()
+}
+
+type usecase struct {
+	serviceName string
+	logger      *logrus.Logger
+	checks      []Check
+	timeout     time.Duration
+	draining    atomic.Bool
+}
+
+func NewHealthUsecase(property UsecaseHealthProperty) *usecase {
+	timeout := property.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &usecase{
+		serviceName: property.ServiceName,
+		logger:      property.Logger,
+		checks:      property.Checks,
+		timeout:     timeout,
+	}
+}
+
+// Liveness the process is up and serving, dependencies aren't checked
+func (u *usecase) Liveness(ctx context.Context) response.Response {
+	return response.NewSuccessResponse(map[string]string{"status": StatusUp}, response.StatOK, aliveMessage)
+}
+
+// Readiness check every dependency, the service is ready when all are up and it isn't shutting down
+func (u *usecase) Readiness(ctx context.Context) response.Response {
+	readiness := model.Readiness{
+		Status: StatusReady,
+		Checks: make([]model.HealthCheck, len(u.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range u.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			readiness.Checks[i] = u.check(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, check := range readiness.Checks {
+		if check.Status != StatusUp {
+			readiness.Status = StatusNotReady
+		}
+	}
+	if u.draining.Load() {
+		readiness.Status = StatusDraining
+	}
+
+	if readiness.Status != StatusReady {
+		return response.NewErrorResponse(exception.ErrServiceUnavailable, http.StatusServiceUnavailable, readiness, response.StatServiceUnavailable, notReadyMessage)
+	}
+
+	return response.NewSuccessResponse(readiness, response.StatOK, readyMessage)
+}
+
+// Drain report not ready from now on so load balancers stop sending requests before shutdown
+func (u *usecase) Drain() {
+	u.draining.Store(true)
+}
+
+func (u *usecase) check(ctx context.Context, check Check) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Checker.Check(ctx)
+
+	result := model.HealthCheck{
+		Name:      check.Name,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		// the error may name hosts or credentials, the unauthenticated probe only gets the status
+		u.logger.WithContext(ctx).WithField("dependency", check.Name).Warn(err)
+		result.Status = StatusDown
+	}
+
+	return result
+}
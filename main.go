@@ -2,25 +2,37 @@ package main
 
 import (
 	"context"
+	"crypto/x509"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt"
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	_ "github.com/joho/godotenv/autoload" // for development
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/audit"
 	"github.com/umerthow/go-oauth/channel"
 	"github.com/umerthow/go-oauth/config"
+	"github.com/umerthow/go-oauth/consent"
+	"github.com/umerthow/go-oauth/device"
+	"github.com/umerthow/go-oauth/health"
+	"github.com/umerthow/go-oauth/metrics"
 	"github.com/umerthow/go-oauth/middleware"
 	"github.com/umerthow/go-oauth/mongodb"
 	"github.com/umerthow/go-oauth/oauth"
+	"github.com/umerthow/go-oauth/ratelimit"
 	"github.com/umerthow/go-oauth/response"
 	"github.com/umerthow/go-oauth/server"
+	"github.com/umerthow/go-oauth/tracing"
+	"github.com/umerthow/go-oauth/usage"
+	"github.com/umerthow/go-oauth/user"
 )
 
 var (
@@ -28,8 +40,14 @@ var (
 	indexMessage string = "Application is running properly"
 )
 
+// closeTimeout time to flush the spans and to close the mongo connections on shutdown
+const closeTimeout = 5 * time.Second
+
 func init() {
-	cfg = config.Load()
+	var err error
+	if cfg, err = config.Load(); err != nil {
+		logrus.Fatal(err)
+	}
 }
 
 func main() {
@@ -46,62 +64,289 @@ func main() {
 		logger.Fatal(err)
 	}
 
-	channelDB := mca.Database(cfg.Mongodb.Database)
+	// Metrics
+	m := metrics.New()
+
+	// Tracing
+	tracerProvider, err := tracing.NewProvider(cfg.Application.Name, cfg.Tracing.Exporter)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.AddHook(tracing.LogHook{})
+	logger.AddHook(middleware.RequestLogHook{})
+
+	channelDB := tracing.InstrumentDatabase(m.InstrumentDatabase(mca.Database(cfg.Mongodb.Database)))
+	channelDB = mongodb.NewRenamedDatabase(channelDB, cfg.Mongodb.Collections.Renames())
+
+	// Audit
+	auditUsecase := audit.NewAuditUsecase(audit.UsecaseAuditProperty{
+		ServiceName:     cfg.Application.Name,
+		Logger:          logger,
+		AuditRepository: audit.NewAuditRepository(logger, channelDB),
+		Location:        cfg.Application.Location,
+		Retention:       cfg.Audit.Retention,
+	})
 
 	// Basic Auth Initialze Middleware
 	// set basic auth middleware
-	basicAuthMiddleware := middleware.NewBasicAuth(cfg.BasicAuth.Username, cfg.BasicAuth.Password)
+	basicAuthMiddleware := middleware.NewBasicAuth(cfg.BasicAuth.Username, cfg.BasicAuth.Password.Value(), auditUsecase)
 	headerMiddleware := middleware.NewHeaderMiddleware(logger)
 
+	// client addresses behind the trusted proxies, for the audit, the access log and the rate limit
+	clientIP, err := middleware.NewClientIP(cfg.AccessLog.TrustedProxies)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
 	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+	router.Use(middleware.RequestContext(clientIP))
+	router.Use(middleware.ClientCertificate)
 	router.HandleFunc("/go-oauth", index)
+	// the metrics name the channels, they are for the operators only
+	router.HandleFunc(metrics.Path, basicAuthMiddleware.Verify(m.Handler().ServeHTTP)).Methods(http.MethodGet)
+
+	// set mutual tls trusted client certificate authorities
+	var clientCAs *x509.CertPool
+	if cfg.TLS.ClientCAFile != "" {
+		pool, err := server.LoadCertPool(cfg.TLS.ClientCAFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		clientCAs = pool
+	}
+
+	// set access log
+	accessLog := middleware.NewAccessLog(logger, router, cfg.AccessLog.Redact, clientIP)
 
 	// set cors
-	handler := cors.New(cors.Options{
-		AllowedOrigins:   cfg.Application.AllowedOrigins,
-		AllowedMethods:   []string{http.MethodPost, http.MethodGet, http.MethodPut, http.MethodDelete},
-		AllowedHeaders:   []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization"},
-		AllowCredentials: true,
-	}).Handler(router)
+	corsPolicy := middleware.NewCORS(corsOptions(cfg))
+	handler := corsPolicy.Handler(accessLog.Handler(m.InstrumentRouter(router)))
 
 	// Channels
 	channelRepository := channel.NewChannelRepository(logger, channelDB)
+	replayRepository := channel.NewReplayRepository(logger, channelDB)
+	clientAuthenticator := channel.NewClientAuthenticator(channel.ClientAuthenticatorProperty{
+		Logger:             logger,
+		ChannelsRepository: channelRepository,
+		ReplayRepository:   replayRepository,
+		KeyResolver:        channel.NewKeyResolver(),
+		Audiences:          []string{cfg.Application.BaseURL + "/go-oauth/v1/token", cfg.JWT.Issuer},
+		ClientCAs:          clientCAs,
+	})
 	channelUsecase := channel.NewChannelUsecase(channel.UsecaseChannelProperty{
 		ServiceName:        cfg.Application.Name,
 		Logger:             logger,
 		ChannelsRepository: channelRepository,
 		Location:           cfg.Application.Location,
+		RegistrationURI:    cfg.Application.BaseURL + channel.RegistrationPath,
+		RegistrationScopes: cfg.Registration.Scopes,
+		AuditRecorder:      auditUsecase,
 	})
 
-	// Oauth
-	oauthUsecase := oauth.NewOauthUsecase(oauth.UsecaseOauthProperty{
+	// Users
+	userRepository := user.NewUserRepository(logger, channelDB)
+	userUsecase := user.NewUserUsecase(user.UsecaseUserProperty{
+		ServiceName:     cfg.Application.Name,
+		Logger:          logger,
+		UsersRepository: userRepository,
+		Location:        cfg.Application.Location,
+	})
+	sessionRepository := user.NewSessionRepository(logger, channelDB)
+	sessionManager := user.NewSessionManager(sessionRepository, cfg.Application.Location, strings.HasPrefix(cfg.Application.BaseURL, "https://"))
+
+	// Consents
+	consentRepository := consent.NewConsentRepository(logger, channelDB)
+	consentUsecase := consent.NewConsentUsecase(consent.UsecaseConsentProperty{
 		ServiceName:        cfg.Application.Name,
 		Logger:             logger,
+		ConsentsRepository: consentRepository,
 		ChannelsRepository: channelRepository,
 		Location:           cfg.Application.Location,
-		JWT: oauth.JWTAccessGenerate{
-			SignedKeyID:  uuid.NewString(),
-			SignedKey:    []byte(cfg.JWT.PrivateKey),
-			SignedMethod: jwt.SigningMethodHS512,
+	})
+
+	// Device Authorization
+	deviceRepository := device.NewDeviceRepository(logger, channelDB)
+	deviceUsecase := device.NewDeviceUsecase(device.UsecaseDeviceProperty{
+		ServiceName:         cfg.Application.Name,
+		Logger:              logger,
+		ClientAuthenticator: clientAuthenticator,
+		DeviceRepository:    deviceRepository,
+		Location:            cfg.Application.Location,
+		VerificationURI:     cfg.Application.BaseURL + "/go-oauth/v1/device",
+	})
+
+	// Usage
+	usageRepository := usage.NewUsageRepository(logger, channelDB)
+	usageUsecase := usage.NewUsageUsecase(usage.UsecaseUsageProperty{
+		ServiceName:     cfg.Application.Name,
+		Logger:          logger,
+		UsageRepository: usageRepository,
+		Location:        cfg.Application.Location,
+	})
+
+	// Oauth
+	pushedAuthorizationRepository := oauth.NewPushedAuthorizationRepository(logger, channelDB)
+	authorizationRepository := oauth.NewAuthorizationRepository(logger, channelDB)
+	refreshTokenRepository := oauth.NewRefreshTokenRepository(logger, channelDB)
+	signingKey := []byte(cfg.JWT.PrivateKey.Value())
+	jwtAccess := oauth.NewJWTAccessGenerate(oauth.KeyID(signingKey), signingKey, jwt.SigningMethodHS512)
+	jwtAccess.Issuer = cfg.JWT.Issuer
+	oauthUsecase := oauth.NewOauthUsecase(oauth.UsecaseOauthProperty{
+		ServiceName:                   cfg.Application.Name,
+		Logger:                        logger,
+		ClientAuthenticator:           clientAuthenticator,
+		ChannelsRepository:            channelRepository,
+		DeviceRepository:              deviceRepository,
+		ConsentRepository:             consentRepository,
+		AuthorizationRepository:       authorizationRepository,
+		PushedAuthorizationRepository: pushedAuthorizationRepository,
+		RefreshTokenRepository:        refreshTokenRepository,
+		UserInfoFinder:                userUsecase,
+		UserStatusChecker:             userUsecase,
+		UsageMeter:                    usageUsecase,
+		AuditRecorder:                 auditUsecase,
+		Location:                      cfg.Application.Location,
+		JWT:                           *jwtAccess,
+		AccessTokenExpiresIn:          cfg.JWT.AccessTokenTTL,
+		RefreshTokenExpiresIn:         cfg.JWT.RefreshTokenTTL,
+	})
+
+	dpopVerifier := oauth.NewDPoPVerifier(cfg.Application.BaseURL, replayRepository, oauth.DPoPNonceKey([]byte(cfg.JWT.PrivateKey.Value())), cfg.DPoP.NonceRequired)
+	bearerAuthMiddleware := middleware.NewBearerAuth(oauth.NewTokenAuthenticator(*jwtAccess, dpopVerifier))
+
+	// Rate Limit
+	lockoutStore := ratelimit.NewLockoutRepository(logger, channelDB)
+	if cfg.RateLimit.Backend == "memory" {
+		lockoutStore = ratelimit.NewMemoryLockoutStore()
+	}
+	rateLimitMiddleware := middleware.NewRateLimit(logger, ratelimit.NewLimiter(ratelimit.LimiterProperty{
+		ClientPerMinute:  cfg.RateLimit.ClientPerMinute,
+		AddressPerMinute: cfg.RateLimit.AddressPerMinute,
+		LockoutThreshold: cfg.RateLimit.LockoutThreshold,
+		LockoutStore:     lockoutStore,
+	}), clientIP)
+
+	// Health
+	healthUsecase := health.NewHealthUsecase(health.UsecaseHealthProperty{
+		ServiceName: cfg.Application.Name,
+		Logger:      logger,
+		Checks: []health.Check{
+			{Name: "mongodb", Checker: health.CheckerFunc(mca.Ping)},
+			{Name: "signing_key", Checker: health.CheckerFunc(jwtAccess.CheckSigningKey)},
 		},
+		Timeout: cfg.Health.Timeout,
 	})
 
+	// Indexes expiring and deduplicating documents, created before serving
+	indexCtx, cancelIndexes := context.WithTimeout(context.Background(), time.Minute)
+	for _, ensureIndexes := range []func(ctx context.Context) error{
+		deviceRepository.EnsureIndexes,
+		replayRepository.EnsureIndexes,
+		userRepository.EnsureIndexes,
+		sessionRepository.EnsureIndexes,
+		authorizationRepository.EnsureIndexes,
+		consentRepository.EnsureIndexes,
+		pushedAuthorizationRepository.EnsureIndexes,
+		refreshTokenRepository.EnsureIndexes,
+		lockoutStore.EnsureIndexes,
+		usageRepository.EnsureIndexes,
+		auditUsecase.EnsureIndexes,
+	} {
+		if err := ensureIndexes(indexCtx); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	cancelIndexes()
+
 	// Routes Handler
+	health.NewHealthHTTPHandler(logger, router, healthUsecase)
 	channel.NewChannelHTTPHandler(logger, vld, router, basicAuthMiddleware, channelUsecase)
-	oauth.NewOauthHTTPHandler(logger, vld, router, headerMiddleware, oauthUsecase)
+	channel.NewRegistrationHTTPHandler(logger, vld, router, middleware.NewStaticBearerAuth(cfg.Registration.InitialAccessTokens), channelUsecase)
+	oauth.NewOauthHTTPHandler(logger, vld, router, headerMiddleware, bearerAuthMiddleware, rateLimitMiddleware, m.InstrumentOauthUsecase(oauthUsecase), dpopVerifier)
+	oauth.NewAuthorizeHTTPHandler(logger, router, oauthUsecase, sessionManager)
+	device.NewDeviceHTTPHandler(logger, vld, router, headerMiddleware, rateLimitMiddleware, deviceUsecase, sessionManager)
+	consent.NewConsentHTTPHandler(logger, router, bearerAuthMiddleware, consentUsecase)
+	user.NewUserHTTPHandler(logger, vld, router, basicAuthMiddleware, userUsecase, sessionManager)
+	usage.NewUsageHTTPHandler(logger, vld, router, basicAuthMiddleware, usageUsecase)
+	audit.NewAuditHTTPHandler(logger, vld, router, basicAuthMiddleware, auditUsecase)
 
 	// initiate server
-	srv := server.NewServer(logger, handler, cfg.Application.Port)
-	srv.Start()
+	timeouts := server.Timeouts{
+		Read:  cfg.Server.ReadTimeout,
+		Write: cfg.Server.WriteTimeout,
+		Idle:  cfg.Server.IdleTimeout,
+	}
+	srv := server.NewServer(logger, handler, cfg.Application.Port, timeouts)
+	if cfg.TLS.CertFile != "" {
+		srv = server.NewTLSServer(logger, handler, cfg.Application.Port, timeouts, cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientAuth)
+	}
+	if err := srv.Start(); err != nil {
+		logger.Fatal(err)
+	}
+
+	// reload the signing key, the dpop nonce key and the cors policy without a restart
+	configWatcher := config.NewWatcher(logger, cfg)
+	configWatcher.OnReload(func(previous, next *config.Config) {
+		if next.JWT.PrivateKey != previous.JWT.PrivateKey {
+			key := []byte(next.JWT.PrivateKey.Value())
+			jwtAccess.RotateKey(oauth.SigningKey{ID: oauth.KeyID(key), Key: key})
+			dpopVerifier.RotateNonceKey(oauth.DPoPNonceKey(key))
+		}
+		if !reflect.DeepEqual(next.CORS, previous.CORS) {
+			corsPolicy.Update(corsOptions(next))
+		}
+	})
+
+	// background workers
+	srv.Go(configWatcher.Run)
 
 	sigterm := make(chan os.Signal, 1)
 	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
-	<-sigterm
 
-	// closing service for a gracefull shutdown.
-	srv.Close()
-	mca.Disconnect(context.Background())
+	exitCode := 0
+	select {
+	case <-sigterm:
+	case err := <-srv.Err():
+		logger.Error(err)
+		exitCode = 1
+	}
+
+	// closing service for a gracefull shutdown, load balancers see us not
+	// ready and stop sending requests before the listener is closed.
+	healthUsecase.Drain()
+	time.Sleep(cfg.Server.DrainDelay)
 
+	// every step has its own deadline, a shutdown using all of its time
+	// doesn't leave the spans unflushed nor the mongo connections open
+	steps := []struct {
+		shutdown func(ctx context.Context) error
+		timeout  time.Duration
+	}{
+		{srv.Shutdown, cfg.Server.ShutdownTimeout},
+		{tracerProvider.Shutdown, closeTimeout},
+		{mca.Disconnect, closeTimeout},
+	}
+	for _, step := range steps {
+		ctx, cancel := context.WithTimeout(context.Background(), step.timeout)
+		if err := step.shutdown(ctx); err != nil {
+			logger.Error(err)
+			exitCode = 1
+		}
+		cancel()
+	}
+
+	os.Exit(exitCode)
+}
+
+func corsOptions(cfg *config.Config) cors.Options {
+	return cors.Options{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+	}
 }
 
 func index(w http.ResponseWriter, r *http.Request) {
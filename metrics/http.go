@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/umerthow/go-oauth/middleware"
+)
+
+// unmatchedRoute route label of the requests no route matched, keeps the label set bounded
+const unmatchedRoute = "unmatched"
+
+// InstrumentRouter count and time every request served by the router, labelled
+// with the path template of the matched route rather than the raw path
+func (m *Metrics) InstrumentRouter(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		route := unmatchedRoute
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.Route != nil {
+			if template, err := match.Route.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		recorder := middleware.NewStatusRecorder(w)
+		router.ServeHTTP(recorder, r)
+
+		status := strconv.Itoa(recorder.Status)
+		m.requests.WithLabelValues(route, r.Method, status).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
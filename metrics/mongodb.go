@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type instrumentedDatabase struct {
+	mongodb.Database
+	metrics *Metrics
+}
+
+// InstrumentDatabase time every operation of the collections of the database
+func (m *Metrics) InstrumentDatabase(db mongodb.Database) mongodb.Database {
+	return &instrumentedDatabase{db, m}
+}
+
+func (db *instrumentedDatabase) Collection(name string, opts ...*options.CollectionOptions) mongodb.Collection {
+	return &instrumentedCollection{db.Database.Collection(name, opts...), name, db.metrics}
+}
+
+type instrumentedCollection struct {
+	col     mongodb.Collection
+	name    string
+	metrics *Metrics
+}
+
+func (c *instrumentedCollection) observe(operation string, start time.Time, err error) {
+	c.metrics.mongoDuration.WithLabelValues(c.name, operation, mongoOutcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func (c *instrumentedCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) mongodb.SingleResult {
+	start := time.Now()
+	result := c.col.FindOne(ctx, filter, opts...)
+	c.observe("find_one", start, result.Err())
+	return result
+}
+
+func (c *instrumentedCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongodb.SingleResult {
+	start := time.Now()
+	result := c.col.FindOneAndUpdate(ctx, filter, update, opts...)
+	c.observe("find_one_and_update", start, result.Err())
+	return result
+}
+
+func (c *instrumentedCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongodb.Cursor, error) {
+	start := time.Now()
+	cursor, err := c.col.Find(ctx, filter, opts...)
+	c.observe("find", start, err)
+	return cursor, err
+}
+
+func (c *instrumentedCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	start := time.Now()
+	result, err := c.col.InsertOne(ctx, document, opts...)
+	c.observe("insert_one", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	start := time.Now()
+	result, err := c.col.InsertMany(ctx, documents, opts...)
+	c.observe("insert_many", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	start := time.Now()
+	counted, err := c.col.CountDocuments(ctx, filter, opts...)
+	c.observe("count_documents", start, err)
+	return counted, err
+}
+
+func (c *instrumentedCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	start := time.Now()
+	result, err := c.col.DeleteOne(ctx, filter, opts...)
+	c.observe("delete_one", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	start := time.Now()
+	result, err := c.col.DeleteMany(ctx, filter, opts...)
+	c.observe("delete_many", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	start := time.Now()
+	result, err := c.col.UpdateMany(ctx, filter, update, opts...)
+	c.observe("update_many", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	start := time.Now()
+	result, err := c.col.UpdateOne(ctx, filter, update, opts...)
+	c.observe("update_one", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	start := time.Now()
+	result, err := c.col.BulkWrite(ctx, models, opts...)
+	c.observe("bulk_write", start, err)
+	return result, err
+}
+
+func (c *instrumentedCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (mongodb.Cursor, error) {
+	start := time.Now()
+	cursor, err := c.col.Aggregate(ctx, pipeline, opts...)
+	c.observe("aggregate", start, err)
+	return cursor, err
+}
+
+func (c *instrumentedCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	start := time.Now()
+	names, err := c.col.CreateIndexes(ctx, models, opts...)
+	c.observe("create_indexes", start, err)
+	return names, err
+}
+
+func (c *instrumentedCollection) DropIndex(ctx context.Context, name string, opts ...*options.DropIndexesOptions) error {
+	start := time.Now()
+	err := c.col.DropIndex(ctx, name, opts...)
+	c.observe("drop_index", start, err)
+	return err
+}
+
+// mongoOutcome a missing document is an answer, not a failed operation
+func mongoOutcome(err error) string {
+	if err == mongo.ErrNoDocuments {
+		return "success"
+	}
+	return outcome(err)
+}
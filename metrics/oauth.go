@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/oauth"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// unknown label of the values that can't be trusted to be bounded
+const unknown = "unknown"
+
+type instrumentedOauthUsecase struct {
+	oauth.Usecase
+	metrics *Metrics
+}
+
+// InstrumentOauthUsecase count the token requests and verifications going through the usecase
+func (m *Metrics) InstrumentOauthUsecase(usecase oauth.Usecase) oauth.Usecase {
+	return &instrumentedOauthUsecase{usecase, m}
+}
+
+func (u *instrumentedOauthUsecase) RequestToken(ctx context.Context, payload model.TokenRequest) response.Response {
+	resp := u.Usecase.RequestToken(ctx, payload)
+	u.metrics.tokens.WithLabelValues(clientId(ctx), grantType(payload.GrantTypes), outcome(resp.Error())).Inc()
+	return resp
+}
+
+func (u *instrumentedOauthUsecase) VerifyToken(ctx context.Context, payload model.TokenVerify) response.Response {
+	resp := u.Usecase.VerifyToken(ctx, payload)
+	u.metrics.verifications.WithLabelValues(clientId(ctx), outcome(resp.Error())).Inc()
+	return resp
+}
+
+// clientId only a channel that was authenticated is a label, the client id
+// sent by the caller is any string and would grow the series without bound
+func clientId(ctx context.Context) string {
+	if clientId := entity.GetAuthenticatedClientIdFromContext(ctx); clientId != "" {
+		return clientId
+	}
+	return unknown
+}
+
+// grantType the grant types the service knows, any other is unknown
+func grantType(grantType entity.GrantType) string {
+	switch grantType {
+	case entity.AuthorizationCode, entity.ClientCredentials, entity.DeviceCode,
+		entity.TokenExchange, entity.JWTBearer, entity.Refreshing:
+		return string(grantType)
+	default:
+		return unknown
+	}
+}
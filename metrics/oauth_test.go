@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/oauth"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// stubOauthUsecase authenticate the client "client" with the secret "secret" only
+type stubOauthUsecase struct {
+	oauth.Usecase
+}
+
+func (u stubOauthUsecase) RequestToken(ctx context.Context, payload model.TokenRequest) response.Response {
+	if payload.ClientId != "client" || payload.ClientSecret != "secret" {
+		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, "invalid client")
+	}
+	entity.SetAuthenticatedClientIdInContext(ctx, payload.ClientId)
+	return response.NewSuccessResponse(nil, response.StatOK, "ok")
+}
+
+func TestTokenLabelsAreBounded(t *testing.T) {
+	m := New()
+	usecase := m.InstrumentOauthUsecase(stubOauthUsecase{})
+
+	request := func(clientId, secret string, grantType entity.GrantType) {
+		ctx := context.WithValue(context.Background(), entity.RequestContextKey{}, new(entity.Request))
+		payload := model.TokenRequest{GrantTypes: grantType}
+		payload.ClientId = clientId
+		payload.ClientSecret = secret
+		usecase.RequestToken(ctx, payload)
+	}
+
+	request("client", "secret", entity.ClientCredentials)
+	request("made-up-1", "guess", entity.ClientCredentials)
+	request("made-up-2", "guess", "made-up-grant")
+
+	if got := testutil.ToFloat64(m.tokens.WithLabelValues("client", string(entity.ClientCredentials), "success")); got != 1 {
+		t.Errorf("authenticated client counted %v times, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.tokens.WithLabelValues(unknown, string(entity.ClientCredentials), "failure")); got != 1 {
+		t.Errorf("unauthenticated client counted %v times as unknown, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.tokens.WithLabelValues(unknown, unknown, "failure")); got != 1 {
+		t.Errorf("unknown grant type counted %v times as unknown, want 1", got)
+	}
+	// the caller's values never become series
+	if got := testutil.CollectAndCount(m.tokens); got != 3 {
+		t.Errorf("%d series, want 3", got)
+	}
+}
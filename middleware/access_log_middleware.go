@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/umerthow/go-oauth/entity"
+)
+
+const (
+	accessLogMessage = "access"
+	redactedValue    = "[REDACTED]"
+)
+
+// alwaysRedacted fields that are never logged whatever the configured list is
+var alwaysRedacted = []string{"clientSecret", "client_secret", "token", "Authorization"}
+
+// AccessLog write one structured line per request served by the router
+type AccessLog struct {
+	logger   *logrus.Logger
+	router   *mux.Router
+	redacted map[string]struct{}
+	clientIP *ClientIP
+}
+
+// NewAccessLog log the requests of the router. The values of the query
+// parameters and headers named in redact are masked, names are case
+// insensitive. The client address is resolved by clientIP.
+func NewAccessLog(logger *logrus.Logger, router *mux.Router, redact []string, clientIP *ClientIP) *AccessLog {
+	accessLog := &AccessLog{
+		logger:   logger,
+		router:   router,
+		redacted: make(map[string]struct{}),
+		clientIP: clientIP,
+	}
+
+	for _, name := range append(alwaysRedacted, redact...) {
+		accessLog.redacted[strings.ToLower(name)] = struct{}{}
+	}
+
+	return accessLog
+}
+
+// Handler log the requests served by next, which wraps the router. The request
+// scope is created here so the client id the handlers learn ends up in the line.
+func (a *AccessLog) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		request := new(entity.Request)
+		ctx := context.WithValue(r.Context(), entity.RequestContextKey{}, request)
+
+		recorder := NewStatusRecorder(w)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		fields := logrus.Fields{
+			"method":     r.Method,
+			"route":      a.route(r, request),
+			"status":     recorder.Status,
+			"bytes":      recorder.Bytes,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip":  a.clientIP.Address(r),
+			"user_agent": a.header(r, "User-Agent"),
+		}
+		if query := r.URL.Query(); len(query) > 0 {
+			fields["query"] = a.redactQuery(query)
+		}
+		if request.ID != "" {
+			fields["request_id"] = request.ID
+		}
+		if request.ClientId != "" {
+			fields["client_id"] = request.ClientId
+		}
+		if deviceId := a.header(r, DeviceId); deviceId != "" {
+			fields["device_id"] = deviceId
+		}
+
+		a.logger.WithContext(ctx).WithFields(fields).Info(accessLogMessage)
+	})
+}
+
+// route path template of the matched route, the path itself when none matched
+func (a *AccessLog) route(r *http.Request, request *entity.Request) string {
+	if request.Route != "" {
+		return request.Route
+	}
+
+	var match mux.RouteMatch
+	if a.router.Match(r, &match) && match.Route != nil {
+		if template, err := match.Route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
+
+func (a *AccessLog) isRedacted(name string) bool {
+	_, ok := a.redacted[strings.ToLower(name)]
+	return ok
+}
+
+// redactQuery query of the request with the values of the redacted parameters masked
+func (a *AccessLog) redactQuery(query url.Values) url.Values {
+	for name, values := range query {
+		if a.isRedacted(name) {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return query
+}
+
+// header value of the request header, masked when it is redacted
+func (a *AccessLog) header(r *http.Request, name string) string {
+	value := r.Header.Get(name)
+	if value != "" && a.isRedacted(name) {
+		return redactedValue
+	}
+	return value
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAccessLogNeverLogsSecrets(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		header http.Header
+		body   string
+		redact []string
+		secret string
+	}{
+		{name: "client secret in the query", target: "/token?clientId=client&clientSecret=s3cr3t-query", secret: "s3cr3t-query"},
+		{name: "client secret in snake case", target: "/token?client_secret=s3cr3t-snake", secret: "s3cr3t-snake"},
+		{name: "token in the query", target: "/token?token=t0ken-query", secret: "t0ken-query"},
+		{name: "token name in another case", target: "/token?TOKEN=t0ken-upper", secret: "t0ken-upper"},
+		{name: "authorization header", target: "/token", header: http.Header{"Authorization": {"Basic YXV0aC1oZWFkZXI="}}, secret: "YXV0aC1oZWFkZXI="},
+		{name: "client secret in the body", target: "/token", body: `{"clientSecret":"s3cr3t-body"}`, secret: "s3cr3t-body"},
+		{name: "configured list leaves the defaults", target: "/token?clientSecret=s3cr3t-configured&code=abc", redact: []string{"code"}, secret: "s3cr3t-configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&out)
+			logger.SetFormatter(&logrus.JSONFormatter{})
+
+			router := mux.NewRouter()
+			router.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+			clientIP, err := NewClientIP(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			NewAccessLog(logger, router, tt.redact, clientIP).Handler(router).ServeHTTP(httptest.NewRecorder(), req)
+
+			line := out.String()
+			if !strings.Contains(line, accessLogMessage) {
+				t.Fatalf("no access line written: %q", line)
+			}
+			if strings.Contains(line, tt.secret) {
+				t.Errorf("secret %q logged: %s", tt.secret, line)
+			}
+		})
+	}
+}
@@ -1,24 +1,44 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/umerthow/go-oauth/entity"
 	"github.com/umerthow/go-oauth/exception"
 	"github.com/umerthow/go-oauth/response"
 )
 
 const (
 	errorMessage = "Invalid token"
+
+	// adminLoginAuditInterval at most one failed login event per address in
+	// this interval, the failures in between are counted into the next one
+	adminLoginAuditInterval = time.Minute
+	// maxAdminLoginAuditAddresses addresses tracked, beyond them the failures
+	// of new addresses share a single event
+	maxAdminLoginAuditAddresses = 10000
 )
 
+// AuditRecorder write security events to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+}
+
 // BasicAuth is a concrete struct of basic auth verifier.
 type BasicAuth struct {
 	username, password string
+	audit              AuditRecorder
+	failures           *failureThrottle
 }
 
-// NewBasicAuth is a constructor.
-func NewBasicAuth(username, password string) RouteMiddleware {
-	return &BasicAuth{username, password}
+// NewBasicAuth is a constructor. Failed logins are recorded when audit is not
+// nil, aggregated per address so guessing can't flood the audit log.
+func NewBasicAuth(username, password string, audit AuditRecorder) RouteMiddleware {
+	return &BasicAuth{username, password, audit, newFailureThrottle(adminLoginAuditInterval, maxAdminLoginAuditAddresses)}
 }
 
 func (ba *BasicAuth) respondUnauthorized(w http.ResponseWriter) {
@@ -36,9 +56,94 @@ func (ba *BasicAuth) Verify(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		if !(username == ba.username && password == ba.password) {
+			ba.recordFailure(r, username)
 			ba.respondUnauthorized(w)
 			return
 		}
-		next(w, r)
+
+		ctx := context.WithValue(r.Context(), entity.ActorContextKey{}, username)
+		next(w, r.WithContext(ctx))
 	})
 }
+
+func (ba *BasicAuth) recordFailure(r *http.Request, username string) {
+	if ba.audit == nil {
+		return
+	}
+
+	failures, ok := ba.failures.Add(entity.GetRemoteAddressFromContext(r.Context()), time.Now())
+	if !ok {
+		return
+	}
+
+	ba.audit.Record(r.Context(), entity.AuditEvent{
+		Type:    entity.AuditAdminLoginFailed,
+		Actor:   username,
+		Outcome: entity.AuditFailure,
+		Reason:  errorMessage,
+		Details: map[string]string{"path": r.URL.Path, "failures": strconv.Itoa(failures)},
+	})
+}
+
+// failureThrottle count failures per key and let one through per interval
+type failureThrottle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	maxKeys  int
+	keys     map[string]*throttledFailures
+}
+
+type throttledFailures struct {
+	recordedAt time.Time
+	count      int
+}
+
+func newFailureThrottle(interval time.Duration, maxKeys int) *failureThrottle {
+	return &failureThrottle{
+		interval: interval,
+		maxKeys:  maxKeys,
+		keys:     make(map[string]*throttledFailures),
+	}
+}
+
+// Add count a failure of the key. ok is true when it is to be recorded, with
+// the failures since the last one recorded, this one included.
+func (t *failureThrottle) Add(key string, now time.Time) (failures int, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, found := t.keys[key]
+	if !found {
+		if len(t.keys) >= t.maxKeys {
+			t.prune(now)
+		}
+		if len(t.keys) >= t.maxKeys {
+			key = ""
+			entry, found = t.keys[key]
+		}
+		if !found {
+			entry = &throttledFailures{}
+			t.keys[key] = entry
+		}
+	}
+
+	entry.count++
+	if !entry.recordedAt.IsZero() && now.Sub(entry.recordedAt) < t.interval {
+		return 0, false
+	}
+
+	failures = entry.count
+	entry.recordedAt = now
+	entry.count = 0
+	return failures, true
+}
+
+// prune forget the keys whose interval is over, the failures they held back
+// are dropped and the next failure of the key is recorded right away
+func (t *failureThrottle) prune(now time.Time) {
+	for key, entry := range t.keys {
+		if now.Sub(entry.recordedAt) >= t.interval {
+			delete(t.keys, key)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailureThrottle(t *testing.T) {
+	throttle := newFailureThrottle(time.Minute, 2)
+	start := time.Unix(1700000000, 0)
+
+	if failures, ok := throttle.Add("203.0.113.1", start); !ok || failures != 1 {
+		t.Fatalf("first failure = (%d, %v), want (1, true)", failures, ok)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, ok := throttle.Add("203.0.113.1", start.Add(time.Duration(i)*time.Second)); ok {
+			t.Fatalf("failure %d within the interval recorded", i+1)
+		}
+	}
+
+	// the failures held back are counted into the next event
+	if failures, ok := throttle.Add("203.0.113.1", start.Add(time.Minute)); !ok || failures != 6 {
+		t.Errorf("failure after the interval = (%d, %v), want (6, true)", failures, ok)
+	}
+
+	// beyond the tracked addresses the new ones share one event
+	throttle.Add("198.51.100.7", start.Add(time.Minute))
+	if failures, ok := throttle.Add("192.0.2.1", start.Add(time.Minute)); !ok || failures != 1 {
+		t.Errorf("first untracked address = (%d, %v), want (1, true)", failures, ok)
+	}
+	if _, ok := throttle.Add("192.0.2.2", start.Add(time.Minute)); ok {
+		t.Error("second untracked address recorded within the interval of the shared event")
+	}
+	if len(throttle.keys) > 3 {
+		t.Errorf("%d keys tracked, want at most the limit and the shared one", len(throttle.keys))
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	errorInvalidTokenMessage = "Invalid access token"
+)
+
+// TokenAuthenticator verify an access token presented on a request and
+// return the context carrying its claims.
+type TokenAuthenticator interface {
+	AuthenticateToken(r *http.Request, token string) (ctx context.Context, err error)
+}
+
+// BearerAuth is a concrete struct of bearer token verifier.
+type BearerAuth struct {
+	authenticator TokenAuthenticator
+}
+
+// NewBearerAuth is a constructor.
+func NewBearerAuth(authenticator TokenAuthenticator) RouteMiddleware {
+	return &BearerAuth{authenticator}
+}
+
+func (ba *BearerAuth) respondUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	resp := response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorInvalidTokenMessage)
+	response.JSON(w, resp)
+}
+
+// Verify will verify the request to ensure it comes with a valid access token.
+// Both Bearer and DPoP schemes are accepted, the authenticator checks the binding.
+func (ba *BearerAuth) Verify(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, ok := strings.Cut(r.Header.Get(header), " ")
+		if !ok || !(strings.EqualFold(scheme, "Bearer") || strings.EqualFold(scheme, "DPoP")) || token == "" {
+			ba.respondUnauthorized(w)
+			return
+		}
+
+		ctx, err := ba.authenticator.AuthenticateToken(r, token)
+		if err != nil {
+			ba.respondUnauthorized(w)
+			return
+		}
+
+		next(w, r.WithContext(ctx))
+	})
+}
+
+// BearerToken return the token of a Bearer authorization header
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/umerthow/go-oauth/entity"
+)
+
+// ClientCertificate put the TLS client certificate and the intermediates sent
+// with it into the request context. It wraps the router, requests without a
+// certificate pass through untouched.
+func ClientCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			ctx := context.WithValue(r.Context(), entity.CertificateContextKey{}, r.TLS.PeerCertificates[0])
+			ctx = context.WithValue(ctx, entity.CertificateIntermediatesContextKey{}, r.TLS.PeerCertificates[1:])
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP resolve the address of the client of a request. X-Forwarded-For
+// is only believed when the peer is one of the trusted proxies, anyone else
+// could forge it to pass for another address.
+type ClientIP struct {
+	trustedProxies []*net.IPNet
+}
+
+// NewClientIP trustedProxies are given as IPs or CIDRs
+func NewClientIP(trustedProxies []string) (*ClientIP, error) {
+	clientIP := &ClientIP{}
+
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		clientIP.trustedProxies = append(clientIP.trustedProxies, network)
+	}
+
+	return clientIP, nil
+}
+
+// Address the peer address, or the closest untrusted address of
+// X-Forwarded-For when the request comes through trusted proxies
+func (c *ClientIP) Address(r *http.Request) string {
+	address := peerAddress(r)
+	if !c.isTrustedProxy(address) {
+		return address
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		address = hop
+		if !c.isTrustedProxy(hop) {
+			break
+		}
+	}
+	return address
+}
+
+func (c *ClientIP) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range c.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerAddress address of the connection the request came in on
+func peerAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/rs/cors"
+)
+
+// CORS cross-origin policy of the service, it can be replaced while serving
+type CORS struct {
+	policy atomic.Pointer[cors.Cors]
+}
+
+// NewCORS is a constructor.
+func NewCORS(options cors.Options) *CORS {
+	c := new(CORS)
+	c.Update(options)
+	return c
+}
+
+// Update apply the options to the requests from now on
+func (c *CORS) Update(options cors.Options) {
+	c.policy.Store(cors.New(options))
+}
+
+// Handler apply the current policy before next
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.policy.Load().ServeHTTP(w, r, next.ServeHTTP)
+	})
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	errorTooManyRequestsMessage = "Too many requests"
+	errorLockedOutMessage       = "Too many failed client authentications"
+
+	// maxPeekBodySize largest body read to find the client id
+	maxPeekBodySize = 1 << 20
+)
+
+// RateLimiter decide whether a client id from an address may make another
+// request, and learn from the failed client authentications
+type RateLimiter interface {
+	Allow(ctx context.Context, clientId, address string) (limit entity.RateLimit, err error)
+	RecordFailure(ctx context.Context, clientId, address string) (err error)
+	RecordSuccess(ctx context.Context, clientId, address string) (err error)
+}
+
+// RateLimit is a concrete struct of the rate limit middleware.
+type RateLimit struct {
+	logger   *logrus.Logger
+	limiter  RateLimiter
+	clientIP *ClientIP
+}
+
+// NewRateLimit is a constructor.
+func NewRateLimit(logger *logrus.Logger, limiter RateLimiter, clientIP *ClientIP) RouteMiddleware {
+	return &RateLimit{logger, limiter, clientIP}
+}
+
+// Verify reject the request with 429 once a bucket is empty or the client is
+// locked out. Client credentials the next handler rejected, flagged with
+// entity.SetInvalidClientInContext, count as a failed client authentication
+// and a success clears the failures. The limiter failing lets the request
+// through, the endpoint stays available without it.
+func (rl *RateLimit) Verify(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		clientId := peekClientId(r)
+		address := rl.clientIP.Address(r)
+		entity.SetClientIdInContext(ctx, clientId)
+
+		limit, err := rl.limiter.Allow(ctx, clientId, address)
+		if err != nil {
+			rl.logger.WithContext(ctx).Error(err)
+			next(w, r)
+			return
+		}
+
+		setRateLimitHeaders(w, limit)
+		if limit.Locked {
+			rl.respondTooManyRequests(w, limit, errorLockedOutMessage)
+			return
+		}
+		if !limit.Allowed {
+			rl.respondTooManyRequests(w, limit, errorTooManyRequestsMessage)
+			return
+		}
+
+		recorder := NewStatusRecorder(w)
+		next(recorder, r)
+
+		if clientId == "" {
+			return
+		}
+		switch {
+		case entity.IsInvalidClientInContext(ctx):
+			err = rl.limiter.RecordFailure(ctx, clientId, address)
+		case recorder.Status < http.StatusBadRequest:
+			err = rl.limiter.RecordSuccess(ctx, clientId, address)
+		}
+		if err != nil {
+			rl.logger.WithContext(ctx).Error(err)
+		}
+	})
+}
+
+func (rl *RateLimit) respondTooManyRequests(w http.ResponseWriter, limit entity.RateLimit, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(seconds(limit.RetryAfter)))
+	resp := response.NewErrorResponse(exception.ErrTooManyRequests, http.StatusTooManyRequests, nil, response.StatTooManyRequests, message)
+	response.JSON(w, resp)
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, limit entity.RateLimit) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(seconds(limit.Reset)))
+}
+
+// peekClientId read the client id of a JSON or form body and put the body
+// back for the handler. A client authenticating with an assertion is known by
+// its issuer, read without verifying the signature since it only picks the
+// counters the request is charged to.
+func peekClientId(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBodySize))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var clientId, assertion string
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		clientId, assertion = form.Get("client_id"), form.Get("client_assertion")
+	} else {
+		var payload struct {
+			ClientId        string `json:"clientId"`
+			ClientAssertion string `json:"clientAssertion"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+		clientId, assertion = payload.ClientId, payload.ClientAssertion
+	}
+
+	if clientId != "" {
+		return clientId
+	}
+	if assertion != "" {
+		return assertionIssuer(assertion)
+	}
+	if username, _, ok := r.BasicAuth(); ok {
+		return username
+	}
+	return ""
+}
+
+// assertionIssuer iss of an unverified JWT, empty when it can't be read
+func assertionIssuer(assertion string) string {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Issuer
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
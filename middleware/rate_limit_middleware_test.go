@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+)
+
+// recordingLimiter RateLimiter allowing every request and remembering the outcomes
+type recordingLimiter struct {
+	failures  []string
+	successes []string
+}
+
+func (l *recordingLimiter) Allow(ctx context.Context, clientId, address string) (entity.RateLimit, error) {
+	return entity.RateLimit{Allowed: true, Limit: 10, Remaining: 9}, nil
+}
+
+func (l *recordingLimiter) RecordFailure(ctx context.Context, clientId, address string) error {
+	l.failures = append(l.failures, clientId+"|"+address)
+	return nil
+}
+
+func (l *recordingLimiter) RecordSuccess(ctx context.Context, clientId, address string) error {
+	l.successes = append(l.successes, clientId+"|"+address)
+	return nil
+}
+
+func newTestRateLimit(t *testing.T, limiter RateLimiter, trustedProxies ...string) RouteMiddleware {
+	clientIP, err := NewClientIP(trustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRateLimit(logrus.New(), limiter, clientIP)
+}
+
+func serveToken(rateLimit RouteMiddleware, next http.HandlerFunc, req *http.Request) {
+	ctx := context.WithValue(req.Context(), entity.RequestContextKey{}, new(entity.Request))
+	rateLimit.Verify(next)(httptest.NewRecorder(), req.WithContext(ctx))
+}
+
+func TestRateLimitCountsOnlyRejectedClients(t *testing.T) {
+	limiter := &recordingLimiter{}
+	rateLimit := newTestRateLimit(t, limiter)
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", strings.NewReader(`{"clientId":"client"}`))
+		req.RemoteAddr = "203.0.113.1:51234"
+		return req
+	}
+
+	// a 401 for a grant type the client isn't allowed isn't a failed authentication
+	serveToken(rateLimit, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}, newRequest())
+	if len(limiter.failures) != 0 {
+		t.Errorf("failures %v recorded for a refusal that isn't an invalid client", limiter.failures)
+	}
+
+	serveToken(rateLimit, func(w http.ResponseWriter, r *http.Request) {
+		entity.SetInvalidClientInContext(r.Context())
+		w.WriteHeader(http.StatusUnauthorized)
+	}, newRequest())
+	if len(limiter.failures) != 1 || limiter.failures[0] != "client|203.0.113.1" {
+		t.Errorf("failures %v, want client|203.0.113.1", limiter.failures)
+	}
+
+	serveToken(rateLimit, func(w http.ResponseWriter, r *http.Request) {}, newRequest())
+	if len(limiter.successes) != 1 {
+		t.Errorf("successes %v, want one", limiter.successes)
+	}
+}
+
+func TestRateLimitAddressBehindTrustedProxy(t *testing.T) {
+	limiter := &recordingLimiter{}
+	rateLimit := newTestRateLimit(t, limiter, "10.0.0.0/8")
+	invalidClient := func(w http.ResponseWriter, r *http.Request) {
+		entity.SetInvalidClientInContext(r.Context())
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", strings.NewReader(`{"clientId":"client"}`))
+	req.RemoteAddr = "10.1.2.3:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.2")
+	serveToken(rateLimit, invalidClient, req)
+
+	// an untrusted peer can't pick its address
+	req = httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", strings.NewReader(`{"clientId":"client"}`))
+	req.RemoteAddr = "203.0.113.1:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	serveToken(rateLimit, invalidClient, req)
+
+	want := []string{"client|198.51.100.7", "client|203.0.113.1"}
+	if strings.Join(limiter.failures, ",") != strings.Join(want, ",") {
+		t.Errorf("failures %v, want %v", limiter.failures, want)
+	}
+}
+
+func TestPeekClientId(t *testing.T) {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"jwt-client","sub":"jwt-client"}`))
+	assertion := "eyJhbGciOiJSUzI1NiJ9." + claims + ".c2lnbmF0dXJl"
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		basicAuth   string
+		want        string
+	}{
+		{name: "json client id", contentType: "application/json", body: `{"clientId":"client"}`, want: "client"},
+		{name: "json assertion", contentType: "application/json", body: `{"clientAssertion":"` + assertion + `"}`, want: "jwt-client"},
+		{name: "form client id", contentType: "application/x-www-form-urlencoded", body: "client_id=form-client", want: "form-client"},
+		{name: "form assertion", contentType: "application/x-www-form-urlencoded; charset=utf-8", body: "client_assertion=" + assertion, want: "jwt-client"},
+		{name: "basic auth", contentType: "application/x-www-form-urlencoded", body: "grant_type=client_credentials", basicAuth: "basic-client", want: "basic-client"},
+		{name: "unreadable", contentType: "application/json", body: `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.basicAuth != "" {
+				req.SetBasicAuth(tt.basicAuth, "secret")
+			}
+
+			if got := peekClientId(req); got != tt.want {
+				t.Errorf("peekClientId() = %q, want %q", got, tt.want)
+			}
+
+			// the handler still reads the whole body
+			if body, _ := io.ReadAll(req.Body); string(body) != tt.body {
+				t.Errorf("body %q left for the handler, want %q", body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// requestIDPattern request ids accepted from the caller, anything else is replaced
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestContext put the client address and the request scope into the
+// request context so logs and events can be traced back to them. The request
+// id of the caller is kept when it is well formed, otherwise one is
+// generated, and it is echoed in the response header.
+func RequestContext(clientIP *ClientIP) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return requestContext(clientIP, next)
+	}
+}
+
+func requestContext(clientIP *ClientIP, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), entity.RemoteAddressContextKey{}, clientIP.Address(r))
+
+		// the access log creates the scope when it wraps the router
+		request := entity.GetRequestFromContext(ctx)
+		if request == nil {
+			request = new(entity.Request)
+			ctx = context.WithValue(ctx, entity.RequestContextKey{}, request)
+		}
+
+		request.ID = r.Header.Get(response.RequestIDHeader)
+		if !requestIDPattern.MatchString(request.ID) {
+			request.ID = uuid.NewString()
+		}
+		if route := mux.CurrentRoute(r); route != nil {
+			request.Route, _ = route.GetPathTemplate()
+		}
+		w.Header().Set(response.RequestIDHeader, request.ID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestLogHook add the request id, client id and route to the entries logged WithContext
+type RequestLogHook struct{}
+
+func (RequestLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (RequestLogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	request := entity.GetRequestFromContext(entry.Context)
+	if request == nil {
+		return nil
+	}
+
+	if request.ID != "" {
+		entry.Data["request_id"] = request.ID
+	}
+	if request.Route != "" {
+		entry.Data["route"] = request.Route
+	}
+	if request.ClientId != "" {
+		entry.Data["client_id"] = request.ClientId
+	}
+	return nil
+}
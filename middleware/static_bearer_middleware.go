@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// StaticBearerAuth is a concrete struct of a verifier accepting a fixed set of bearer tokens.
+type StaticBearerAuth struct {
+	tokens []string
+}
+
+// NewStaticBearerAuth is a constructor. Without tokens every request is rejected.
+func NewStaticBearerAuth(tokens []string) RouteMiddleware {
+	return &StaticBearerAuth{tokens}
+}
+
+func (sa *StaticBearerAuth) respondUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	resp := response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorInvalidTokenMessage)
+	response.JSON(w, resp)
+}
+
+// Verify will verify the request to ensure it comes with one of the configured tokens.
+func (sa *StaticBearerAuth) Verify(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := BearerToken(r)
+		if !ok || !sa.valid(token) {
+			sa.respondUnauthorized(w)
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+func (sa *StaticBearerAuth) valid(token string) bool {
+	valid := false
+	for _, t := range sa.tokens {
+		if t != "" && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
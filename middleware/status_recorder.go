@@ -0,0 +1,26 @@
+package middleware
+
+import "net/http"
+
+// StatusRecorder remember the status code and the size of the body written by the next handler
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int
+}
+
+// NewStatusRecorder the status is http.StatusOK until the handler writes another one
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (rec *StatusRecorder) WriteHeader(status int) {
+	rec.Status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *StatusRecorder) Write(b []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(b)
+	rec.Bytes += n
+	return n, err
+}
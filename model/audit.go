@@ -0,0 +1,19 @@
+package model
+
+// AuditQuery filters of the audit log, times are RFC 3339
+type AuditQuery struct {
+	Actor    string
+	ClientId string
+	Type     string
+	From     string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page     int    `validate:"gte=1"`
+	Limit    int    `validate:"gte=1,lte=1000"`
+}
+
+// Pagination position of a page in the whole result
+type Pagination struct {
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
+}
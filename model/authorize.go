@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientId            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// RequestURI reference to a pushed authorization request or to a request object
+	RequestURI string
+	// Request signed request object, its parameters take precedence over the others
+	Request string
+	// Signed the parameters were verified from a signed request object
+	Signed bool
+	// RequestObjectID jti of the request object passed by value or by
+	// reference, recorded when the code is issued so it is used once
+	RequestObjectID        string
+	RequestObjectExpiresAt time.Time
+}
+
+// PushedAuthorizationRequest authorization request pushed by the channel (RFC 9126 section 2.1)
+type PushedAuthorizationRequest struct {
+	ClientAuthentication
+	ResponseType        string `json:"responseType" validate:"required_without=Request"`
+	RedirectURI         string `json:"redirectUri" validate:"required_without=Request"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	Nonce               string `json:"nonce"`
+	CodeChallenge       string `json:"codeChallenge"`
+	CodeChallengeMethod string `json:"codeChallengeMethod"`
+	Request             string `json:"request"`
+}
+
+type PushedAuthorizationResponse struct {
+	RequestURI string `json:"requestUri"`
+	ExpiresIn  int64  `json:"expiresIn"`
+}
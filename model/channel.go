@@ -3,11 +3,34 @@ package model
 import "github.com/umerthow/go-oauth/entity"
 
 type RequestChannel struct {
-	Name        string             `json:"name" validate:"required"`
-	ClientType  string             `json:"clientType" validate:"oneof=public confidential"`
-	GrantTypes  []entity.GrantType `json:"grantTypes" validate:"required"`
-	Scopes      []string           `json:"scopes" validate:"required"`
-	RedirectURI string             `json:"redirectUri" validate:"required"`
+	Name                               string                `json:"name" validate:"required"`
+	ClientType                         string                `json:"clientType" validate:"oneof=public confidential"`
+	GrantTypes                         []entity.GrantType    `json:"grantTypes" validate:"required"`
+	Scopes                             []string              `json:"scopes" validate:"required"`
+	RedirectURI                        string                `json:"redirectUri" validate:"required"`
+	RedirectURIs                       []string              `json:"redirectUris" validate:"omitempty,dive,url"`
+	ExchangePolicy                     entity.ExchangePolicy `json:"exchangePolicy"`
+	TokenEndpointAuthMethod            entity.AuthMethod     `json:"tokenEndpointAuthMethod" validate:"omitempty,oneof=client_secret_post private_key_jwt tls_client_auth self_signed_tls_client_auth"`
+	PublicKey                          string                `json:"publicKey" validate:"required_if=TokenEndpointAuthMethod private_key_jwt JwksURI ''"`
+	JwksURI                            string                `json:"jwksUri" validate:"omitempty,url"`
+	TLSClientAuthSubjectDN             string                `json:"tlsClientAuthSubjectDn" validate:"required_if=TokenEndpointAuthMethod tls_client_auth"`
+	TLSClientCertificateThumbprint     string                `json:"tlsClientCertificateThumbprint" validate:"required_if=TokenEndpointAuthMethod self_signed_tls_client_auth"`
+	TLSClientCertificateBoundTokens    bool                  `json:"tlsClientCertificateBoundAccessTokens"`
+	DPoPBoundAccessTokens              bool                  `json:"dpopBoundAccessTokens"`
+	ConsentExempt                      bool                  `json:"consentExempt"`
+	RequirePushedAuthorizationRequests bool                  `json:"requirePushedAuthorizationRequests"`
+	RequireSignedRequestObject         bool                  `json:"requireSignedRequestObject"`
+	RequestURIs                        []string              `json:"requestUris" validate:"omitempty,dive,url"`
+	PostLogoutRedirectURIs             []string              `json:"postLogoutRedirectUris" validate:"omitempty,dive,url"`
+	BackchannelLogoutURI               string                `json:"backchannelLogoutUri" validate:"omitempty,url"`
+	Quota                              RequestQuota          `json:"quota"`
+}
+
+// RequestQuota token quota of a channel, zero means unlimited
+type RequestQuota struct {
+	Daily        int64 `json:"daily" validate:"gte=0"`
+	Monthly      int64 `json:"monthly" validate:"gte=0"`
+	AlertPercent int   `json:"alertPercent" validate:"gte=0,lte=100"`
 }
 
 type ClientInfo interface {
@@ -0,0 +1,9 @@
+package model
+
+// ClientAuthentication credentials presented by a channel to authenticate itself
+type ClientAuthentication struct {
+	ClientId            string `json:"clientId" validate:"required"`
+	ClientSecret        string `json:"clientSecret" validate:"required_without=ClientAssertion"`
+	ClientAssertionType string `json:"clientAssertionType" validate:"required_with=ClientAssertion"`
+	ClientAssertion     string `json:"clientAssertion"`
+}
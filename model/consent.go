@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type ConsentScope struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+type ConsentResponse struct {
+	ClientId    string         `json:"client_id"`
+	ChannelName string         `json:"channel_name"`
+	Scopes      []ConsentScope `json:"scopes"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
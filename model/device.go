@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type DeviceAuthorizationRequest struct {
+	ClientAuthentication
+	Scope string `json:"scope"`
+}
+
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type DeviceVerification struct {
+	UserCode string
+	Approved bool
+	UserID   string
+	AuthTime time.Time
+	AMR      []string
+}
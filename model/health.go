@@ -0,0 +1,14 @@
+package model
+
+// HealthCheck outcome of the check of a dependency, why it failed is only logged
+type HealthCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// Readiness whether the service can serve requests, with the checks it is based on
+type Readiness struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
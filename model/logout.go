@@ -0,0 +1,9 @@
+package model
+
+// EndSessionRequest logout request of a channel (OIDC RP-Initiated Logout section 2)
+type EndSessionRequest struct {
+	IDTokenHint           string
+	ClientId              string
+	PostLogoutRedirectURI string
+	State                 string
+}
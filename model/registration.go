@@ -0,0 +1,31 @@
+package model
+
+import "github.com/umerthow/go-oauth/entity"
+
+// ClientMetadata client metadata of a dynamic registration request (RFC 7591 section 2)
+type ClientMetadata struct {
+	ClientName                         string             `json:"client_name" validate:"required"`
+	RedirectURIs                       []string           `json:"redirect_uris" validate:"omitempty,dive,url"`
+	GrantTypes                         []entity.GrantType `json:"grant_types"`
+	TokenEndpointAuthMethod            entity.AuthMethod  `json:"token_endpoint_auth_method"`
+	Scope                              string             `json:"scope"`
+	JwksURI                            string             `json:"jwks_uri" validate:"omitempty,url"`
+	TLSClientAuthSubjectDN             string             `json:"tls_client_auth_subject_dn"`
+	RequirePushedAuthorizationRequests bool               `json:"require_pushed_authorization_requests"`
+	RequireSignedRequestObject         bool               `json:"require_signed_request_object"`
+	RequestURIs                        []string           `json:"request_uris" validate:"omitempty,dive,url"`
+	PostLogoutRedirectURIs             []string           `json:"post_logout_redirect_uris" validate:"omitempty,dive,url"`
+	BackchannelLogoutURI               string             `json:"backchannel_logout_uri" validate:"omitempty,url"`
+}
+
+// ClientInformation registered metadata and credentials (RFC 7591 section 3.2.1, RFC 7592 section 3)
+type ClientInformation struct {
+	ClientId              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret,omitempty"`
+	ClientIdIssuedAt      int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt int64  `json:"client_secret_expires_at"`
+	// RegistrationAccessToken only returned when the client is registered
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+	ClientMetadata
+}
@@ -7,16 +7,35 @@ import (
 )
 
 type TokenRequest struct {
-	ClientId     string           `json:"clientId"  validate:"required"`
-	ClientSecret string           `json:"clientSecret" validate:"required"`
-	GrantTypes   entity.GrantType `json:"grantTypes" validate:"required"`
+	ClientAuthentication
+	GrantTypes entity.GrantType `json:"grantTypes" validate:"required"`
+	DeviceCode string           `json:"deviceCode" validate:"required_if=GrantTypes urn:ietf:params:oauth:grant-type:device_code"`
+	Scope      string           `json:"scope"`
+	Audience   string           `json:"audience"`
+	// token exchange (RFC 8693)
+	SubjectToken       string `json:"subjectToken" validate:"required_if=GrantTypes urn:ietf:params:oauth:grant-type:token-exchange"`
+	SubjectTokenType   string `json:"subjectTokenType" validate:"required_with=SubjectToken"`
+	ActorToken         string `json:"actorToken"`
+	ActorTokenType     string `json:"actorTokenType" validate:"required_with=ActorToken"`
+	RequestedTokenType string `json:"requestedTokenType"`
+	// jwt bearer grant (RFC 7523)
+	Assertion string `json:"assertion" validate:"required_if=GrantTypes urn:ietf:params:oauth:grant-type:jwt-bearer"`
+	// authorization code grant (RFC 6749 section 4.1.3)
+	Code         string `json:"code" validate:"required_if=GrantTypes authorization_code"`
+	RedirectURI  string `json:"redirectUri" validate:"required_if=GrantTypes authorization_code"`
+	CodeVerifier string `json:"codeVerifier"`
+	// refresh token grant (RFC 6749 section 6)
+	RefreshToken string `json:"refreshToken" validate:"required_if=GrantTypes refresh_token"`
 }
 
 type TokenClaimResponse struct {
-	TokenType    string    `json:"tokenType"`
-	ExpiredAt    time.Time `json:"expiredAt"`
-	Token        string    `json:"token"`
-	RefreshToken string    `json:"refreshToken,omitempty"`
+	TokenType       string    `json:"tokenType"`
+	ExpiredAt       time.Time `json:"expiredAt"`
+	Token           string    `json:"token"`
+	RefreshToken    string    `json:"refreshToken,omitempty"`
+	IssuedTokenType string    `json:"issuedTokenType,omitempty"`
+	IDToken         string    `json:"idToken,omitempty"`
+	Scope           string    `json:"scope,omitempty"`
 }
 
 type TokenVerify struct {
@@ -27,4 +46,8 @@ type TokenVerify struct {
 type TokenVerifyResponse struct {
 	ClientId string   `json:"clientId"`
 	Scopes   []string `json:"scopes"`
+	// Cnf key the token is bound to, the resource server accepts it only
+	// from a presenter proving that key or certificate
+	Cnf *entity.Confirmation `json:"cnf,omitempty"`
+	AMR []string             `json:"amr,omitempty"`
 }
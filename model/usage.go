@@ -0,0 +1,16 @@
+package model
+
+// UsageQuery date range of a usage report, dates are YYYY-MM-DD and both ends are included
+type UsageQuery struct {
+	ClientId    string `validate:"omitempty"`
+	From        string `validate:"required,datetime=2006-01-02"`
+	To          string `validate:"required,datetime=2006-01-02"`
+	Granularity string `validate:"omitempty,oneof=day month"`
+}
+
+// UsageResponse tokens issued to a channel during a day or a month of the range
+type UsageResponse struct {
+	ClientId string `json:"clientId" bson:"client_id"`
+	Period   string `json:"period" bson:"period"`
+	Count    int64  `json:"count" bson:"count"`
+}
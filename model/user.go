@@ -0,0 +1,26 @@
+package model
+
+type RequestUser struct {
+	Username      string `json:"username" validate:"required"`
+	Password      string `json:"password" validate:"required,min=8"`
+	Email         string `json:"email" validate:"omitempty,email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"givenName"`
+	FamilyName    string `json:"familyName"`
+}
+
+type RequestUserStatus struct {
+	IsActive *bool `json:"isActive" validate:"required_without=IsLocked"`
+	IsLocked *bool `json:"isLocked"`
+}
+
+type Login struct {
+	Username string
+	Password string
+}
+
+type TOTPEnrolment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
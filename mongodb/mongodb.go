@@ -12,6 +12,7 @@ type Client interface {
 	Connect(ctx context.Context) (err error)
 	Database(name string, opts ...*options.DatabaseOptions) (db Database)
 	Disconnect(ctx context.Context) (err error)
+	Ping(ctx context.Context) (err error)
 }
 
 // Database is a collection of behavior of mongodb database.
@@ -22,6 +23,7 @@ type Database interface {
 // Collection is a collection of behavior of mongodb client.
 type Collection interface {
 	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) (result SingleResult)
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (result SingleResult)
 	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (cursor Cursor, err error)
 	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (result *mongo.InsertOneResult, err error)
 	InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (result *mongo.InsertManyResult, err error)
@@ -31,6 +33,9 @@ type Collection interface {
 	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (result *mongo.UpdateResult, err error)
 	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (result *mongo.UpdateResult, err error)
 	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (result *mongo.BulkWriteResult, err error)
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (cursor Cursor, err error)
+	CreateIndexes(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) (names []string, err error)
+	DropIndex(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (err error)
 }
 
 // SingleResult is a collectioin of function of mongodb single result.
@@ -60,3 +60,8 @@ func (c *ClientAdapter) Disconnect(ctx context.Context) (err error) {
 	err = c.client.Disconnect(ctx)
 	return
 }
+
+// Ping sends a ping command to verify that the primary of the deployment is reachable.
+func (c *ClientAdapter) Ping(ctx context.Context) (err error) {
+	return c.client.Ping(ctx, readpref.Primary())
+}
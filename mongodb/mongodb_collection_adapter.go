@@ -26,6 +26,22 @@ func (col *CollectionAdapter) FindOne(ctx context.Context, filter interface{}, o
 	return
 }
 
+// FindOneAndUpdate executes a findAndModify command to update at most one document in the collection and returns the
+// document as it was before the update, or after it with the ReturnDocument option.
+//
+// The filter parameter must be a document containing query operators and can be used to select the document to be
+// updated. It cannot be nil. If the filter does not match any documents, a SingleResult with an error set to
+// ErrNoDocuments will be returned, unless the Upsert option inserts one.
+//
+// The opts parameter can be used to specify options for the operation (see the options.FindOneAndUpdateOptions
+// documentation).
+//
+// For more information about the command, see https://docs.mongodb.com/manual/reference/command/findAndModify/.
+func (col *CollectionAdapter) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) (result SingleResult) {
+	result = col.col.FindOneAndUpdate(ctx, filter, update, opts...)
+	return
+}
+
 // Find executes a find command and returns a Cursor over the matching documents in the collection.
 //
 // The filter parameter must be a document containing query operators and can be used to select which documents are
@@ -160,3 +176,39 @@ func (col *CollectionAdapter) BulkWrite(ctx context.Context, models []mongo.Writ
 	result, err = col.col.BulkWrite(ctx, models, opts...)
 	return
 }
+
+// Aggregate executes an aggregate command against the collection and returns a cursor over the resulting documents.
+//
+// The pipeline parameter must be an array of documents, each representing an aggregation stage. The pipeline cannot
+// be nil but can be empty. The stage documents must all be non-nil.
+//
+// The opts parameter can be used to specify options for the operation (see the options.AggregateOptions documentation.)
+//
+// For more information about the command, see https://docs.mongodb.com/manual/reference/command/aggregate/.
+func (col *CollectionAdapter) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (cursor Cursor, err error) {
+	cursor, err = col.col.Aggregate(ctx, pipeline, opts...)
+	return
+}
+
+// CreateIndexes executes a createIndexes command to create the indexes of the models in the collection. Creating an
+// index that exists with the same options is a no-op.
+//
+// The opts parameter can be used to specify options for the operation (see the options.CreateIndexesOptions
+// documentation).
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/createIndexes/.
+func (col *CollectionAdapter) CreateIndexes(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) (names []string, err error) {
+	names, err = col.col.Indexes().CreateMany(ctx, models, opts...)
+	return
+}
+
+// DropIndex executes a dropIndexes command to drop the index with the name from the collection.
+//
+// The opts parameter can be used to specify options for the operation (see the options.DropIndexesOptions
+// documentation).
+//
+// For more information about the command, see https://www.mongodb.com/docs/manual/reference/command/dropIndexes/.
+func (col *CollectionAdapter) DropIndex(ctx context.Context, name string, opts ...*options.DropIndexesOptions) (err error) {
+	_, err = col.col.Indexes().DropOne(ctx, name, opts...)
+	return
+}
@@ -16,3 +16,23 @@ func (db *DatabaseAdapter) Collection(name string, opts ...*options.CollectionOp
 	col = &CollectionAdapter{mongoCollection}
 	return
 }
+
+// RenamedDatabase is a database whose collections are stored under configured names.
+type RenamedDatabase struct {
+	Database
+	names map[string]string
+}
+
+// NewRenamedDatabase is a constructor, names maps the name a collection is asked
+// for to the name it is stored under, unmapped names are kept.
+func NewRenamedDatabase(db Database, names map[string]string) Database {
+	return &RenamedDatabase{Database: db, names: names}
+}
+
+// Collection gets a handle for the collection stored under the configured name.
+func (db *RenamedDatabase) Collection(name string, opts ...*options.CollectionOptions) (col Collection) {
+	if renamed, ok := db.names[name]; ok && renamed != "" {
+		name = renamed
+	}
+	return db.Database.Collection(name, opts...)
+}
@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// index options conflict codes, an index with the same name or keys exists with other options
+const (
+	codeIndexOptionsConflict  = 85
+	codeIndexKeySpecsConflict = 86
+	codeIndexNotFound         = 27
+)
+
+// TTLIndex index on a date field removing a document once it is expireAfter
+// past that date, zero removes it at the date itself.
+func TTLIndex(field string, expireAfter time.Duration) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: field, Value: 1}},
+		Options: options.Index().
+			SetName(field + "_ttl").
+			SetExpireAfterSeconds(int32(expireAfter / time.Second)),
+	}
+}
+
+// Index ascending index on the fields, in that order
+func Index(fields ...string) mongo.IndexModel {
+	keys := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: 1})
+	}
+	return mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName(strings.Join(fields, "_")),
+	}
+}
+
+// UniqueIndex index refusing two documents with the same values of the fields
+func UniqueIndex(fields ...string) mongo.IndexModel {
+	index := Index(fields...)
+	index.Options.SetName(strings.Join(fields, "_") + "_unique").SetUnique(true)
+	return index
+}
+
+// EnsureIndexes create the indexes of the collection. An index that exists
+// with other options, such as an expiry that was reconfigured, is dropped and
+// created again.
+func EnsureIndexes(ctx context.Context, col Collection, models ...mongo.IndexModel) error {
+	for _, model := range models {
+		_, err := col.CreateIndexes(ctx, []mongo.IndexModel{model})
+		if !isIndexConflict(err) || model.Options == nil || model.Options.Name == nil {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := col.DropIndex(ctx, *model.Options.Name); err != nil {
+			return err
+		}
+		if _, err := col.CreateIndexes(ctx, []mongo.IndexModel{model}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropIndexIfExists drop the index, one that doesn't exist is already dropped
+func DropIndexIfExists(ctx context.Context, col Collection, name string) error {
+	err := col.DropIndex(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == codeIndexNotFound || cmdErr.HasErrorMessage("ns not found")) {
+		return nil
+	}
+	return err
+}
+
+func isIndexConflict(err error) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	return cmdErr.Code == codeIndexOptionsConflict || cmdErr.Code == codeIndexKeySpecsConflict
+}
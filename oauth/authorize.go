@@ -0,0 +1,263 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+)
+
+const (
+	// AuthorizationCodeExpiresIn lifetime of an authorization code
+	AuthorizationCodeExpiresIn = time.Minute
+
+	responseTypeCode = "code"
+)
+
+// FindAuthorizeChannel validate the client and redirect uri of an authorization
+// request. Its errors must be shown to the user, never redirected.
+func (u *usecase) FindAuthorizeChannel(ctx context.Context, clientId, redirectURI string) (channel entity.Channel, err error) {
+	ctx, span := tracing.Start(ctx, "oauth.FindAuthorizeChannel")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, clientId)
+
+	channel, err = u.channelRepository.FindByClientId(ctx, clientId)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			err = tokenErr.ErrUnauthorizedClient
+		}
+		return
+	}
+
+	if !channel.IsActive {
+		return entity.Channel{}, tokenErr.ErrUnauthorizedClient
+	}
+
+	if !channel.HasRedirectURI(redirectURI) {
+		return entity.Channel{}, tokenErr.ErrInvalidRedirectURI
+	}
+
+	return
+}
+
+// Authorize issue an authorization code for the user of the session. Its
+// errors are returned to the channel through the redirect uri.
+func (u *usecase) Authorize(ctx context.Context, channel entity.Channel, payload model.AuthorizeRequest, session entity.Session) (code string, err error) {
+	ctx, span := tracing.Start(ctx, "oauth.Authorize")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	if payload.ResponseType != responseTypeCode {
+		return "", tokenErr.ErrUnsupportedResponseType
+	}
+
+	if !channel.HasGrantType(entity.AuthorizationCode) {
+		return "", tokenErr.ErrUnauthorizedClient
+	}
+
+	if channel.RequirePushedAuthorizationRequests && !isPushedRequestURI(payload.RequestURI) {
+		return "", tokenErr.ErrPushedRequestRequired
+	}
+
+	if channel.RequireSignedRequestObject && !payload.Signed {
+		return "", tokenErr.ErrSignedRequestRequired
+	}
+
+	scopes := RequestedScopes(channel, payload.Scope)
+	if !channel.HasScopes(scopes) || !channel.AllowsOpenID(scopes) {
+		return "", tokenErr.ErrInvalidScope
+	}
+
+	method := payload.CodeChallengeMethod
+	if payload.CodeChallenge == "" {
+		// public clients can't keep a secret, PKCE is what protects their codes
+		if channel.ClientType == "public" {
+			return "", tokenErr.ErrMissingCodeChallenge
+		}
+	} else {
+		if method == "" {
+			method = entity.CodeChallengePlain
+		}
+		if method != entity.CodeChallengePlain && method != entity.CodeChallengeS256 {
+			return "", tokenErr.ErrInvalidCodeChallenge
+		}
+	}
+
+	consented, err := u.hasConsent(ctx, channel, session.UserID, scopes)
+	if err != nil {
+		return
+	}
+	if !consented {
+		return "", tokenErr.ErrConsentRequired
+	}
+
+	if isPushedRequestURI(payload.RequestURI) {
+		if err = u.consumePushedAuthorization(ctx, payload.RequestURI); err != nil {
+			return
+		}
+	} else if payload.RequestObjectID != "" {
+		// the object is verified again after the login and consent steps, it
+		// is only used up with the code it produced
+		if err = u.clientAuthenticator.ConsumeRequestObject(ctx, channel.ClientId, payload.RequestObjectID, payload.RequestObjectExpiresAt); err != nil {
+			return
+		}
+	}
+
+	code, err = generateAuthorizationCode()
+	if err != nil {
+		return
+	}
+
+	authorization := entity.Authorization{
+		ID:                  uuid.NewString(),
+		Code:                code,
+		ClientId:            channel.ClientId,
+		UserID:              session.UserID,
+		SessionID:           session.ID,
+		RedirectURI:         payload.RedirectURI,
+		Scopes:              scopes,
+		Nonce:               payload.Nonce,
+		AuthTime:            session.AuthTime,
+		AMR:                 session.AMR,
+		Acr:                 entity.ACR(session.AMR),
+		CodeChallenge:       payload.CodeChallenge,
+		CodeChallengeMethod: method,
+		ExpiresAt:           now.Add(AuthorizationCodeExpiresIn),
+		CreatedAt:           now,
+	}
+
+	if err = u.authorizationRepository.InsertOne(ctx, authorization); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// GrantConsent record that the user of the session approved the scopes of
+// the authorization request for the channel
+func (u *usecase) GrantConsent(ctx context.Context, channel entity.Channel, payload model.AuthorizeRequest, session entity.Session) (err error) {
+	ctx, span := tracing.Start(ctx, "oauth.GrantConsent")
+	defer span.End()
+
+	scopes := RequestedScopes(channel, payload.Scope)
+	if !channel.HasScopes(scopes) || !channel.AllowsOpenID(scopes) {
+		return tokenErr.ErrInvalidScope
+	}
+
+	return u.consentRepository.Grant(ctx, session.UserID, channel.ClientId, scopes, time.Now().In(u.loc))
+}
+
+// hasConsent report whether the user already approved every scope, consent
+// exempt channels never ask
+func (u *usecase) hasConsent(ctx context.Context, channel entity.Channel, userID string, scopes []string) (bool, error) {
+	if channel.ConsentExempt {
+		return true, nil
+	}
+
+	consent, err := u.consentRepository.FindOne(ctx, userID, channel.ClientId)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return consent.Covers(scopes), nil
+}
+
+// RequestedScopes scopes of an authorization request, the channel scopes when none are requested
+func RequestedScopes(channel entity.Channel, scope string) []string {
+	if scope == "" {
+		return channel.Scopes
+	}
+	return strings.Fields(scope)
+}
+
+// authorizationCode redeem an authorization code for the user it was issued to (RFC 6749 section 4.1.3)
+func (u *usecase) authorizationCode(ctx context.Context, channel entity.Channel, payload model.TokenRequest) response.Response {
+	now := time.Now().In(u.loc)
+
+	authorization, err := u.authorizationRepository.FindByCode(ctx, payload.Code)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidAuthorizeCode.Error())
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	// consume the code first, a concurrent redemption finds it gone
+	if err := u.authorizationRepository.DeleteOne(ctx, authorization.ID); err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidAuthorizeCode.Error())
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	if authorization.ClientId != channel.ClientId || authorization.IsExpired(now) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidAuthorizeCode.Error())
+	}
+
+	if authorization.RedirectURI != payload.RedirectURI {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidRedirectURI.Error())
+	}
+
+	if err := verifyCodeChallenge(authorization, payload.CodeVerifier); err != nil {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, err.Error())
+	}
+
+	data := u.generateBasic(ctx, channel, authorization.Scopes)
+	data.ID = authorization.UserID
+	data.Nonce = authorization.Nonce
+	data.AuthTime = authorization.AuthTime
+	data.AMR = authorization.AMR
+	data.Acr = authorization.Acr
+	data.SessionID = authorization.SessionID
+
+	return u.issueToken(ctx, data)
+}
+
+// verifyCodeChallenge check the PKCE verifier against the challenge of the code (RFC 7636 section 4.6)
+func verifyCodeChallenge(authorization entity.Authorization, verifier string) error {
+	if authorization.CodeChallenge == "" {
+		return nil
+	}
+
+	if verifier == "" {
+		return tokenErr.ErrMissingCodeVerifier
+	}
+
+	challenge := verifier
+	if authorization.CodeChallengeMethod == entity.CodeChallengeS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(authorization.CodeChallenge)) != 1 {
+		return tokenErr.ErrInvalidCodeChallenge
+	}
+
+	return nil
+}
+
+func generateAuthorizationCode() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,274 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/user"
+)
+
+const (
+	// AuthorizePath authorization endpoint of the code flow
+	AuthorizePath = "/go-oauth/v1/authorize"
+
+	errorInvalidCSRFMessage = "Invalid CSRF Token"
+)
+
+type AuthorizeHTTPHandler struct {
+	Logger   *logrus.Logger
+	Usecase  Usecase
+	Sessions *user.SessionManager
+}
+
+func NewAuthorizeHTTPHandler(logger *logrus.Logger, router *mux.Router, usecase Usecase, sessions *user.SessionManager) {
+	handler := &AuthorizeHTTPHandler{
+		Logger:   logger,
+		Usecase:  usecase,
+		Sessions: sessions,
+	}
+
+	router.HandleFunc(AuthorizePath, handler.Authorize).Methods(http.MethodGet)
+	router.HandleFunc(AuthorizePath, handler.Consent).Methods(http.MethodPost)
+	router.HandleFunc(EndSessionPath, handler.EndSession).Methods(http.MethodGet, http.MethodPost)
+}
+
+func (handler *AuthorizeHTTPHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	payload, ok := handler.resolveRequest(w, r, authorizeRequest(r.URL.Query()))
+	if !ok {
+		return
+	}
+
+	channel, ok := handler.findChannel(w, r, payload)
+	if !ok {
+		return
+	}
+
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		if err != exception.ErrNotFound {
+			handler.Logger.WithContext(ctx).Error(err)
+		}
+		http.Redirect(w, r, user.LoginURL(r.URL.RequestURI()), http.StatusFound)
+		return
+	}
+
+	handler.issueCode(w, r, channel, payload, session)
+}
+
+// Consent handle the decision of the user on the consent screen
+func (handler *AuthorizeHTTPHandler) Consent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatBadRequest, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+	payload, ok := handler.resolveRequest(w, r, authorizeRequest(r.PostForm))
+	if !ok {
+		return
+	}
+
+	channel, ok := handler.findChannel(w, r, payload)
+	if !ok {
+		return
+	}
+
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		if err != exception.ErrNotFound {
+			handler.Logger.WithContext(ctx).Error(err)
+		}
+		http.Redirect(w, r, user.LoginURL(AuthorizePath+"?"+authorizeQuery(payload).Encode()), http.StatusFound)
+		return
+	}
+
+	if !user.ValidCSRF(session, r.PostForm.Get("csrf_token")) {
+		resp := response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, errorInvalidCSRFMessage)
+		response.JSON(w, resp)
+		return
+	}
+
+	if r.PostForm.Get("action") != "approve" {
+		handler.redirectError(w, r, payload, tokenErr.ErrAccessDenied)
+		return
+	}
+
+	if err := handler.Usecase.GrantConsent(ctx, channel, payload, session); err != nil {
+		handler.redirectError(w, r, payload, err)
+		return
+	}
+
+	handler.issueCode(w, r, channel, payload, session)
+}
+
+// issueCode redirect the user back to the channel with an authorization code,
+// or show the consent screen when the scopes were never approved
+func (handler *AuthorizeHTTPHandler) issueCode(w http.ResponseWriter, r *http.Request, channel entity.Channel, payload model.AuthorizeRequest, session entity.Session) {
+	code, err := handler.Usecase.Authorize(r.Context(), channel, payload, session)
+	if err == tokenErr.ErrConsentRequired {
+		handler.renderConsent(w, channel, payload, session)
+		return
+	}
+	if err != nil {
+		handler.redirectError(w, r, payload, err)
+		return
+	}
+
+	// the channel is told through back-channel logout when the session ends
+	if err := handler.Sessions.AddClient(r.Context(), session, channel.ClientId); err != nil {
+		handler.Logger.WithContext(r.Context()).Error(err)
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if payload.State != "" {
+		params.Set("state", payload.State)
+	}
+
+	http.Redirect(w, r, appendQuery(payload.RedirectURI, params), http.StatusFound)
+}
+
+// resolveRequest load the parameters of a pushed authorization request or a
+// request object. Its errors are shown to the user since the redirect uri
+// can't be trusted yet.
+func (handler *AuthorizeHTTPHandler) resolveRequest(w http.ResponseWriter, r *http.Request, payload model.AuthorizeRequest) (model.AuthorizeRequest, bool) {
+	resolved, err := handler.Usecase.ResolveAuthorizeRequest(r.Context(), payload)
+	if err != nil {
+		switch err {
+		case tokenErr.ErrInvalidRequestURI:
+			resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatInvalidRequestURI, err.Error())
+			response.JSON(w, resp)
+			return payload, false
+		case tokenErr.ErrInvalidRequestObject:
+			resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatInvalidRequestObject, err.Error())
+			response.JSON(w, resp)
+			return payload, false
+		case tokenErr.ErrUnauthorizedClient:
+			resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatBadRequest, err.Error())
+			response.JSON(w, resp)
+			return payload, false
+		}
+		resp := response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		response.JSON(w, resp)
+		return payload, false
+	}
+
+	return resolved, true
+}
+
+// findChannel errors about the client or redirect uri are shown, never redirected
+func (handler *AuthorizeHTTPHandler) findChannel(w http.ResponseWriter, r *http.Request, payload model.AuthorizeRequest) (entity.Channel, bool) {
+	channel, err := handler.Usecase.FindAuthorizeChannel(r.Context(), payload.ClientId, payload.RedirectURI)
+	if err != nil {
+		if err == tokenErr.ErrUnauthorizedClient || err == tokenErr.ErrInvalidRedirectURI {
+			resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatBadRequest, err.Error())
+			response.JSON(w, resp)
+			return channel, false
+		}
+		resp := response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		response.JSON(w, resp)
+		return channel, false
+	}
+
+	return channel, true
+}
+
+func (handler *AuthorizeHTTPHandler) renderConsent(w http.ResponseWriter, channel entity.Channel, payload model.AuthorizeRequest, session entity.Session) {
+	page := consentPage{
+		ChannelName: channel.Name,
+		Request:     payload,
+		CSRFToken:   session.CSRFToken,
+	}
+	for _, scope := range RequestedScopes(channel, payload.Scope) {
+		page.Scopes = append(page.Scopes, model.ConsentScope{Scope: scope, Description: entity.DescribeScope(scope)})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := consentTemplate.Execute(w, page); err != nil {
+		handler.Logger.Error(err)
+	}
+}
+
+// redirectError return the error to the channel (RFC 6749 section 4.1.2.1)
+func (handler *AuthorizeHTTPHandler) redirectError(w http.ResponseWriter, r *http.Request, payload model.AuthorizeRequest, err error) {
+	code := err
+	switch err {
+	case tokenErr.ErrUnsupportedResponseType, tokenErr.ErrUnauthorizedClient, tokenErr.ErrInvalidScope, tokenErr.ErrAccessDenied, tokenErr.ErrInvalidRequestURI,
+		tokenErr.ErrInvalidRequestObject:
+	case tokenErr.ErrMissingCodeChallenge, tokenErr.ErrInvalidCodeChallenge, tokenErr.ErrPushedRequestRequired, tokenErr.ErrSignedRequestRequired:
+		code = tokenErr.ErrInvalidRequest
+	default:
+		handler.Logger.WithContext(r.Context()).Error(err)
+		code = tokenErr.ErrServerError
+	}
+
+	params := url.Values{}
+	params.Set("error", code.Error())
+	if code != err && code != tokenErr.ErrServerError {
+		params.Set("error_description", err.Error())
+	}
+	if payload.State != "" {
+		params.Set("state", payload.State)
+	}
+
+	http.Redirect(w, r, appendQuery(payload.RedirectURI, params), http.StatusFound)
+}
+
+func authorizeRequest(values url.Values) model.AuthorizeRequest {
+	return model.AuthorizeRequest{
+		ResponseType:        values.Get("response_type"),
+		ClientId:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scope:               values.Get("scope"),
+		State:               values.Get("state"),
+		Nonce:               values.Get("nonce"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+		RequestURI:          values.Get("request_uri"),
+		Request:             values.Get("request"),
+	}
+}
+
+// authorizeQuery encode the request back into authorize endpoint parameters
+func authorizeQuery(payload model.AuthorizeRequest) url.Values {
+	params := url.Values{}
+	for key, value := range map[string]string{
+		"response_type":         payload.ResponseType,
+		"client_id":             payload.ClientId,
+		"redirect_uri":          payload.RedirectURI,
+		"scope":                 payload.Scope,
+		"state":                 payload.State,
+		"nonce":                 payload.Nonce,
+		"code_challenge":        payload.CodeChallenge,
+		"code_challenge_method": payload.CodeChallengeMethod,
+		"request_uri":           payload.RequestURI,
+		"request":               payload.Request,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+
+	return params
+}
+
+func appendQuery(uri string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+
+	return uri + separator + params.Encode()
+}
@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+)
+
+// verifier and challenge of RFC 7636 appendix B
+const (
+	rfcCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfcCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization entity.Authorization
+		verifier      string
+		want          error
+	}{
+		{
+			name:          "S256 matching verifier",
+			authorization: entity.Authorization{CodeChallenge: rfcCodeChallenge, CodeChallengeMethod: entity.CodeChallengeS256},
+			verifier:      rfcCodeVerifier,
+		},
+		{
+			name:          "S256 wrong verifier",
+			authorization: entity.Authorization{CodeChallenge: rfcCodeChallenge, CodeChallengeMethod: entity.CodeChallengeS256},
+			verifier:      rfcCodeVerifier + "x",
+			want:          tokenErr.ErrInvalidCodeChallenge,
+		},
+		{
+			name:          "S256 verifier sent as the challenge",
+			authorization: entity.Authorization{CodeChallenge: rfcCodeChallenge, CodeChallengeMethod: entity.CodeChallengeS256},
+			verifier:      rfcCodeChallenge,
+			want:          tokenErr.ErrInvalidCodeChallenge,
+		},
+		{
+			name:          "plain matching verifier",
+			authorization: entity.Authorization{CodeChallenge: rfcCodeVerifier, CodeChallengeMethod: entity.CodeChallengePlain},
+			verifier:      rfcCodeVerifier,
+		},
+		{
+			name:          "plain wrong verifier",
+			authorization: entity.Authorization{CodeChallenge: rfcCodeVerifier, CodeChallengeMethod: entity.CodeChallengePlain},
+			verifier:      rfcCodeChallenge,
+			want:          tokenErr.ErrInvalidCodeChallenge,
+		},
+		{
+			name:          "missing verifier",
+			authorization: entity.Authorization{CodeChallenge: rfcCodeChallenge, CodeChallengeMethod: entity.CodeChallengeS256},
+			want:          tokenErr.ErrMissingCodeVerifier,
+		},
+		{
+			name:          "no challenge requested",
+			authorization: entity.Authorization{},
+			verifier:      rfcCodeVerifier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyCodeChallenge(tt.authorization, tt.verifier); err != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package oauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+const (
+	// DPoPHeader request header carrying the proof
+	DPoPHeader = "DPoP"
+	// DPoPNonceHeader response header carrying the server nonce
+	DPoPNonceHeader = "DPoP-Nonce"
+
+	dpopProofType     = "dpop+jwt"
+	dpopProofMaxAge   = time.Minute * 5
+	dpopClockSkew     = time.Second * 30
+	dpopNonceLifetime = time.Minute * 5
+
+	dpopNonceKeyLabel = "dpop-nonce"
+)
+
+var (
+	ErrInvalidDPoPProof = errors.New("invalid_dpop_proof")
+	ErrUseDPoPNonce     = errors.New("use_dpop_nonce")
+
+	errDPoPProofType    = errors.New("dpop proof typ must be dpop+jwt")
+	errDPoPProofKey     = errors.New("dpop proof must carry a public jwk")
+	errDPoPProofMethod  = errors.New("dpop proof htm does not match the request")
+	errDPoPProofURI     = errors.New("dpop proof htu does not match the request")
+	errDPoPProofAge     = errors.New("dpop proof iat is outside the accepted window")
+	errDPoPProofJTI     = errors.New("dpop proof must contain jti")
+	errDPoPProofReplay  = errors.New("dpop proof has already been used")
+	errDPoPProofAth     = errors.New("dpop proof ath does not match the access token")
+	errDPoPProofBinding = errors.New("dpop proof key does not match the token binding")
+)
+
+type DPoPThumbprintContextKey struct{}
+
+// GetDPoPThumbprintFromContext thumbprint of the key proven on the request, empty without DPoP
+func GetDPoPThumbprintFromContext(ctx context.Context) string {
+	jkt, _ := ctx.Value(DPoPThumbprintContextKey{}).(string)
+
+	return jkt
+}
+
+// DPoPProofClaims claims of a DPoP proof (RFC 9449 section 4.2)
+type DPoPProofClaims struct {
+	ID         string `json:"jti"`
+	HTTPMethod string `json:"htm"`
+	HTTPURI    string `json:"htu"`
+	IssuedAt   int64  `json:"iat"`
+	Ath        string `json:"ath,omitempty"`
+	Nonce      string `json:"nonce,omitempty"`
+}
+
+// Valid implement jwt.Claims
+func (c *DPoPProofClaims) Valid() error {
+	iat := time.Unix(c.IssuedAt, 0)
+	now := time.Now()
+	if iat.After(now.Add(dpopClockSkew)) || iat.Before(now.Add(-dpopProofMaxAge)) {
+		return errDPoPProofAge
+	}
+
+	if c.ID == "" {
+		return errDPoPProofJTI
+	}
+
+	return nil
+}
+
+// DPoPVerifier validate DPoP proofs presented on the token endpoint and with access tokens
+type DPoPVerifier struct {
+	baseURL          string
+	replayRepository channel.ReplayRepository
+	nonceKeys        *KeyRing
+	requireNonce     bool
+}
+
+// DPoPNonceKey key of the nonces derived from the signing key, the signing key
+// itself never signs anything but the tokens
+func DPoPNonceKey(signingKey []byte) []byte {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(dpopNonceKeyLabel))
+	return mac.Sum(nil)
+}
+
+// NewDPoPVerifier is a constructor. The nonce key must be shared by every replica
+// so a nonce issued by one can be checked by another.
+func NewDPoPVerifier(baseURL string, replayRepository channel.ReplayRepository, nonceKey []byte, requireNonce bool) *DPoPVerifier {
+	return &DPoPVerifier{
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		replayRepository: replayRepository,
+		nonceKeys:        NewKeyRing(SigningKey{ID: KeyID(nonceKey), Key: nonceKey}),
+		requireNonce:     requireNonce,
+	}
+}
+
+// RotateNonceKey issue the nonces with the key from now on, the nonces issued
+// with the current one stay valid until they expire
+func (v *DPoPVerifier) RotateNonceKey(key []byte) {
+	v.nonceKeys.Rotate(SigningKey{ID: KeyID(key), Key: key})
+}
+
+// Verify check the proof of the request and return the thumbprint of its key.
+// The access token is required when the proof accompanies a bound token.
+func (v *DPoPVerifier) Verify(r *http.Request, proof string, accessToken string) (string, error) {
+	var jkt string
+
+	claims := &DPoPProofClaims{}
+	_, err := jwt.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, errDPoPProofKey
+		}
+
+		if typ, _ := token.Header["typ"].(string); typ != dpopProofType {
+			return nil, errDPoPProofType
+		}
+
+		raw, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, errDPoPProofKey
+		}
+
+		var key channel.JSONWebKey
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return nil, errDPoPProofKey
+		}
+
+		if jkt, err = key.Thumbprint(); err != nil {
+			return nil, errDPoPProofKey
+		}
+
+		return key.PublicKey()
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.EqualFold(claims.HTTPMethod, r.Method) {
+		return "", errDPoPProofMethod
+	}
+
+	if claims.HTTPURI != v.baseURL+r.URL.Path {
+		return "", errDPoPProofURI
+	}
+
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims.Ath != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", errDPoPProofAth
+		}
+	}
+
+	if v.requireNonce && !v.validNonce(claims.Nonce) {
+		return "", ErrUseDPoPNonce
+	}
+
+	expiresAt := time.Unix(claims.IssuedAt, 0).Add(dpopProofMaxAge)
+	if err := v.replayRepository.Store(r.Context(), "dpop:"+jkt+":"+claims.ID, expiresAt); err != nil {
+		if err == exception.ErrConflict {
+			return "", errDPoPProofReplay
+		}
+		return "", err
+	}
+
+	return jkt, nil
+}
+
+// Nonce return a fresh server nonce, empty when nonces are not required
+func (v *DPoPVerifier) Nonce() string {
+	if !v.requireNonce {
+		return ""
+	}
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(time.Now().Unix()))
+
+	mac := hmac.New(sha256.New, v.nonceKeys.Current().Key)
+	mac.Write(buf)
+
+	return base64.RawURLEncoding.EncodeToString(append(buf, mac.Sum(nil)...))
+}
+
+func (v *DPoPVerifier) validNonce(nonce string) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(raw) != 8+sha256.Size {
+		return false
+	}
+
+	if !v.signedNonce(raw[:8], raw[8:]) {
+		return false
+	}
+
+	issuedAt := time.Unix(int64(binary.BigEndian.Uint64(raw[:8])), 0)
+
+	return time.Since(issuedAt) < dpopNonceLifetime
+}
+
+// signedNonce report whether the MAC was made with the current or the previous nonce key
+func (v *DPoPVerifier) signedNonce(issuedAt, sum []byte) bool {
+	for _, key := range v.nonceKeys.Keys() {
+		mac := hmac.New(sha256.New, key.Key)
+		mac.Write(issuedAt)
+		if hmac.Equal(mac.Sum(nil), sum) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+const testBaseURL = "https://oauth.example.com"
+
+// memoryReplayRepository ReplayRepository keeping the keys in a map
+type memoryReplayRepository map[string]time.Time
+
+func (r memoryReplayRepository) Store(ctx context.Context, key string, expiresAt time.Time) error {
+	if _, ok := r[key]; ok {
+		return exception.ErrConflict
+	}
+	r[key] = expiresAt
+	return nil
+}
+
+func (r memoryReplayRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+// dpopClaims jwt.Claims signing any claims, validation is the verifier's job
+type dpopClaims map[string]interface{}
+
+func (c dpopClaims) Valid() error {
+	return nil
+}
+
+type dpopProver struct {
+	t   *testing.T
+	key *ecdsa.PrivateKey
+	jwk channel.JSONWebKey
+	jti int
+}
+
+func newDPoPProver(t *testing.T) *dpopProver {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dpopProver{t: t, key: key, jwk: channel.JSONWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}}
+}
+
+// proof for the request, the overrides replace claims or remove them when nil
+func (p *dpopProver) proof(method, uri string, overrides dpopClaims) string {
+	p.jti++
+	claims := dpopClaims{"jti": "proof-" + strconv.Itoa(p.jti), "htm": method, "htu": uri, "iat": time.Now().Unix()}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = dpopProofType
+	token.Header["jwk"] = p.jwk
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return signed
+}
+
+// cause error of the proof, jwt wraps the errors of the keyfunc and of Valid
+func cause(err error) error {
+	if validationErr, ok := err.(*jwt.ValidationError); ok && validationErr.Inner != nil {
+		return validationErr.Inner
+	}
+	return err
+}
+
+func TestDPoPVerify(t *testing.T) {
+	prover := newDPoPProver(t)
+	jkt, _ := prover.jwk.Thumbprint()
+	tokenURI := testBaseURL + "/go-oauth/v1/token"
+	accessToken := "access-token"
+	sum := sha256.Sum256([]byte(accessToken))
+	ath := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	replayed := prover.proof(http.MethodPost, tokenURI, nil)
+	hs256 := jwt.NewWithClaims(jwt.SigningMethodHS256, dpopClaims{"jti": "hs", "htm": http.MethodPost, "htu": tokenURI, "iat": time.Now().Unix()})
+	hs256.Header["typ"] = dpopProofType
+	symmetric, _ := hs256.SignedString([]byte("shared-secret"))
+	untyped := jwt.NewWithClaims(jwt.SigningMethodES256, dpopClaims{"jti": "untyped", "htm": http.MethodPost, "htu": tokenURI, "iat": time.Now().Unix()})
+	untyped.Header["jwk"] = prover.jwk
+	withoutType, _ := untyped.SignedString(prover.key)
+
+	tests := []struct {
+		name        string
+		proof       string
+		accessToken string
+		want        error
+	}{
+		{name: "valid", proof: prover.proof(http.MethodPost, tokenURI, nil)},
+		{name: "lowercase method", proof: prover.proof("post", tokenURI, nil)},
+		{name: "first use", proof: replayed},
+		{name: "replayed", proof: replayed, want: errDPoPProofReplay},
+		{name: "other method", proof: prover.proof(http.MethodGet, tokenURI, nil), want: errDPoPProofMethod},
+		{name: "other uri", proof: prover.proof(http.MethodPost, testBaseURL+"/go-oauth/v1/par", nil), want: errDPoPProofURI},
+		{name: "uri with a query", proof: prover.proof(http.MethodPost, tokenURI+"?a=b", nil), want: errDPoPProofURI},
+		{name: "access token hash", proof: prover.proof(http.MethodPost, tokenURI, dpopClaims{"ath": ath}), accessToken: accessToken},
+		{name: "missing access token hash", proof: prover.proof(http.MethodPost, tokenURI, nil), accessToken: accessToken, want: errDPoPProofAth},
+		{name: "hash of another token", proof: prover.proof(http.MethodPost, tokenURI, dpopClaims{"ath": ath}), accessToken: "other-token", want: errDPoPProofAth},
+		{name: "missing jti", proof: prover.proof(http.MethodPost, tokenURI, dpopClaims{"jti": nil}), want: errDPoPProofJTI},
+		{name: "too old", proof: prover.proof(http.MethodPost, tokenURI, dpopClaims{"iat": time.Now().Add(-dpopProofMaxAge - time.Minute).Unix()}), want: errDPoPProofAge},
+		{name: "issued in the future", proof: prover.proof(http.MethodPost, tokenURI, dpopClaims{"iat": time.Now().Add(dpopClockSkew + time.Minute).Unix()}), want: errDPoPProofAge},
+		{name: "symmetric algorithm", proof: symmetric, want: errDPoPProofKey},
+		{name: "missing typ", proof: withoutType, want: errDPoPProofType},
+	}
+
+	verifier := NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, []byte("nonce-key"), false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", nil)
+			got, err := verifier.Verify(r, tt.proof, tt.accessToken)
+			if cause(err) != tt.want {
+				t.Fatalf("Verify() = %v, want %v", err, tt.want)
+			}
+			if err == nil && got != jkt {
+				t.Errorf("thumbprint %q, want %q", got, jkt)
+			}
+		})
+	}
+}
+
+func TestDPoPNonce(t *testing.T) {
+	prover := newDPoPProver(t)
+	tokenURI := testBaseURL + "/go-oauth/v1/token"
+	verifier := NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, []byte("nonce-key"), true)
+	otherReplica := NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, []byte("nonce-key"), true)
+	otherKey := NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, []byte("other-key"), true)
+
+	nonce := verifier.Nonce()
+	if nonce == "" {
+		t.Fatal("no nonce issued while they are required")
+	}
+	if NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, []byte("nonce-key"), false).Nonce() != "" {
+		t.Error("nonce issued while they aren't required")
+	}
+
+	tests := []struct {
+		name     string
+		verifier *DPoPVerifier
+		nonce    interface{}
+		want     error
+	}{
+		{name: "valid", verifier: verifier, nonce: nonce},
+		{name: "issued by another replica", verifier: otherReplica, nonce: nonce},
+		{name: "missing", verifier: verifier, want: ErrUseDPoPNonce},
+		{name: "made up", verifier: verifier, nonce: "bm9uY2U", want: ErrUseDPoPNonce},
+		{name: "issued with another key", verifier: otherKey, nonce: nonce, want: ErrUseDPoPNonce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", nil)
+			if _, err := tt.verifier.Verify(r, prover.proof(http.MethodPost, tokenURI, dpopClaims{"nonce": tt.nonce}), ""); err != tt.want {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDPoPNonceKeyIsNotTheSigningKey(t *testing.T) {
+	signingKey := []byte("signing-key")
+	nonceKey := DPoPNonceKey(signingKey)
+
+	if string(nonceKey) == string(signingKey) {
+		t.Fatal("nonce key is the signing key")
+	}
+	if string(DPoPNonceKey(signingKey)) != string(nonceKey) {
+		t.Error("nonce key differs between replicas sharing the signing key")
+	}
+	if string(DPoPNonceKey([]byte("other-signing-key"))) == string(nonceKey) {
+		t.Error("nonce key unchanged by a rotation of the signing key")
+	}
+
+	prover := newDPoPProver(t)
+	tokenURI := testBaseURL + "/go-oauth/v1/token"
+	verifier := NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, nonceKey, true)
+	nonce := NewDPoPVerifier(testBaseURL, memoryReplayRepository{}, signingKey, true).Nonce()
+
+	r := httptest.NewRequest(http.MethodPost, "/go-oauth/v1/token", nil)
+	if _, err := verifier.Verify(r, prover.proof(http.MethodPost, tokenURI, dpopClaims{"nonce": nonce}), ""); err != ErrUseDPoPNonce {
+		t.Errorf("nonce made with the signing key: Verify() = %v, want %v", err, ErrUseDPoPNonce)
+	}
+}
@@ -0,0 +1,212 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/tracing"
+)
+
+const (
+	// BackchannelLogoutTimeout time a channel has to acknowledge a logout token
+	BackchannelLogoutTimeout = time.Second * 5
+	// BackchannelLogoutDeadline time the logout tokens of a session are tried
+	// for, including the wait for a free slot
+	BackchannelLogoutDeadline = time.Second * 30
+	// BackchannelLogoutConcurrency logout tokens posted at the same time
+	// across all the sessions ending
+	BackchannelLogoutConcurrency = 16
+)
+
+var backchannelLogoutClient = newBackchannelLogoutClient()
+
+// newBackchannelLogoutClient the registered uri can't reach our own network
+// and the logout token must reach that uri, never another one
+func newBackchannelLogoutClient() *http.Client {
+	client := channel.NewPublicHTTPClient(BackchannelLogoutTimeout)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// ValidateEndSession check the logout request of a channel. It returns where
+// to send the user afterwards, empty when the request names no valid
+// destination, and the subject of the id_token_hint.
+func (u *usecase) ValidateEndSession(ctx context.Context, payload model.EndSessionRequest) (redirectURI, subject string, err error) {
+	ctx, span := tracing.Start(ctx, "oauth.ValidateEndSession")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, payload.ClientId)
+
+	clientId := payload.ClientId
+	if payload.IDTokenHint != "" {
+		audience, err := idTokenAudience(payload.IDTokenHint)
+		if err != nil {
+			return "", "", err
+		}
+		if clientId != "" && clientId != audience {
+			return "", "", tokenErr.ErrInvalidIDTokenHint
+		}
+		clientId = audience
+	}
+
+	if clientId == "" {
+		if payload.PostLogoutRedirectURI != "" {
+			return "", "", tokenErr.ErrInvalidPostLogoutURI
+		}
+		return "", "", nil
+	}
+
+	channel, err := u.channelRepository.FindByClientId(ctx, clientId)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			err = tokenErr.ErrUnauthorizedClient
+		}
+		return "", "", err
+	}
+
+	if payload.IDTokenHint != "" {
+		claims, err := u.jwt.VerifyIDTokenHint(ctx, channel, payload.IDTokenHint)
+		if err != nil {
+			return "", "", tokenErr.ErrInvalidIDTokenHint
+		}
+		subject = claims.Subject
+	}
+
+	if payload.PostLogoutRedirectURI == "" {
+		return "", subject, nil
+	}
+
+	if !channel.HasPostLogoutRedirectURI(payload.PostLogoutRedirectURI) {
+		return "", "", tokenErr.ErrInvalidPostLogoutURI
+	}
+
+	redirectURI = payload.PostLogoutRedirectURI
+	if payload.State != "" {
+		redirectURI = appendQuery(redirectURI, url.Values{"state": {payload.State}})
+	}
+
+	return redirectURI, subject, nil
+}
+
+// EndSession revoke the refresh tokens issued during the session and tell
+// the channels that took part in it. The channels are told in the
+// background, a channel that is slow or can't be reached neither delays the
+// logout nor keeps the user logged in.
+func (u *usecase) EndSession(ctx context.Context, session entity.Session) (err error) {
+	ctx, span := tracing.Start(ctx, "oauth.EndSession")
+	defer span.End()
+
+	revoked, err := u.refreshTokenRepository.DeleteBySessionID(ctx, session.ID)
+	if err != nil {
+		return
+	}
+	if revoked > 0 {
+		u.record(ctx, entity.AuditEvent{
+			Type:    entity.AuditTokenRevoked,
+			Actor:   session.UserID,
+			Outcome: entity.AuditSuccess,
+			Reason:  "end session",
+			Details: map[string]string{"refresh_tokens": strconv.FormatInt(revoked, 10)},
+		})
+	}
+
+	var channels []entity.Channel
+	for _, clientId := range session.ClientIds {
+		channel, err := u.channelRepository.FindByClientId(ctx, clientId)
+		if err != nil {
+			if err != exception.ErrNotFound {
+				u.logger.WithContext(ctx).Error(err)
+			}
+			continue
+		}
+
+		// a logout token is signed with the client secret, a channel without one can't verify it
+		if channel.BackchannelLogoutURI == "" || channel.SecretKey == "" {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+
+	if len(channels) == 0 {
+		return nil
+	}
+
+	// the request ends before the channels answer, its trace and logger fields are kept
+	logoutCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), BackchannelLogoutDeadline)
+	var wg sync.WaitGroup
+	for _, channel := range channels {
+		wg.Add(1)
+		go func(channel entity.Channel) {
+			defer wg.Done()
+			if err := u.backchannelLogout(logoutCtx, channel, session); err != nil {
+				u.logger.WithContext(logoutCtx).WithField("client_id", channel.ClientId).Warn(err)
+			}
+		}(channel)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
+	return nil
+}
+
+// backchannelLogout post a logout token to the channel (OIDC Back-Channel
+// Logout section 2.5) once one of the BackchannelLogoutConcurrency slots is free
+func (u *usecase) backchannelLogout(ctx context.Context, channel entity.Channel, session entity.Session) error {
+	select {
+	case u.logoutSlots <- struct{}{}:
+		defer func() { <-u.logoutSlots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	logoutToken, err := u.jwt.LogoutToken(ctx, channel, session, time.Now().In(u.loc))
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{"logout_token": {logoutToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.BackchannelLogoutURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := backchannelLogoutClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return tokenErr.New("back-channel logout answered " + resp.Status)
+	}
+
+	return nil
+}
+
+// idTokenAudience read the channel an ID token was issued to, the signature
+// is verified once the channel and its secret are known
+func idTokenAudience(idToken string) (string, error) {
+	claims := &IDTokenClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(idToken, claims); err != nil || claims.Audience == "" {
+		return "", tokenErr.ErrInvalidIDTokenHint
+	}
+
+	return claims.Audience, nil
+}
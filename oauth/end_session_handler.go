@@ -0,0 +1,107 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/user"
+)
+
+// EndSessionPath end session endpoint of RP-initiated logout
+const EndSessionPath = "/go-oauth/v1/end_session"
+
+// EndSession log the user out at the request of a channel. Without an
+// id_token_hint of the current user the user confirms first, so a third
+// party page can't log anyone out.
+func (handler *AuthorizeHTTPHandler) EndSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatBadRequest, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+	payload := endSessionRequest(r.Form)
+
+	redirectURI, subject, err := handler.Usecase.ValidateEndSession(ctx, payload)
+	if err != nil {
+		switch err {
+		case tokenErr.ErrInvalidIDTokenHint:
+			resp := response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatInvalidIDTokenHint, err.Error())
+			response.JSON(w, resp)
+		case tokenErr.ErrInvalidPostLogoutURI:
+			resp := response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatInvalidPostLogoutURI, err.Error())
+			response.JSON(w, resp)
+		case tokenErr.ErrUnauthorizedClient:
+			resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatBadRequest, err.Error())
+			response.JSON(w, resp)
+		default:
+			resp := response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+			response.JSON(w, resp)
+		}
+		return
+	}
+
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		if err != exception.ErrNotFound {
+			handler.Logger.WithContext(ctx).Error(err)
+		}
+		handler.loggedOut(w, r, redirectURI)
+		return
+	}
+
+	confirmed := subject != "" && subject == session.UserID
+	if r.Method == http.MethodPost && user.ValidCSRF(session, r.PostForm.Get("csrf_token")) {
+		confirmed = true
+	}
+	if !confirmed {
+		handler.renderLogout(w, logoutPage{Request: payload, CSRFToken: session.CSRFToken})
+		return
+	}
+
+	if err := handler.Usecase.EndSession(ctx, session); err != nil {
+		resp := response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.Sessions.Destroy(ctx, w, session); err != nil {
+		resp := response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	handler.loggedOut(w, r, redirectURI)
+}
+
+// loggedOut send the user back to the channel, or tell them they are signed out
+func (handler *AuthorizeHTTPHandler) loggedOut(w http.ResponseWriter, r *http.Request, redirectURI string) {
+	if redirectURI != "" {
+		http.Redirect(w, r, redirectURI, http.StatusFound)
+		return
+	}
+
+	handler.renderLogout(w, logoutPage{LoggedOut: true})
+}
+
+func (handler *AuthorizeHTTPHandler) renderLogout(w http.ResponseWriter, page logoutPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := logoutTemplate.Execute(w, page); err != nil {
+		handler.Logger.Error(err)
+	}
+}
+
+func endSessionRequest(values url.Values) model.EndSessionRequest {
+	return model.EndSessionRequest{
+		IDTokenHint:           values.Get("id_token_hint"),
+		ClientId:              values.Get("client_id"),
+		PostLogoutRedirectURI: values.Get("post_logout_redirect_uri"),
+		State:                 values.Get("state"),
+	}
+}
@@ -1,6 +1,7 @@
 package oauth
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,17 +19,21 @@ type HTTPHandler struct {
 	Logger   *logrus.Logger
 	Validate *validator.Validate
 	Usecase  Usecase
+	DPoP     *DPoPVerifier
 }
 
-func NewOauthHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, middleware middleware.RouteMiddleware, usecase Usecase) {
+func NewOauthHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, middleware middleware.RouteMiddleware, bearerAuth middleware.RouteMiddleware, rateLimit middleware.RouteMiddleware, usecase Usecase, dpop *DPoPVerifier) {
 	handler := &HTTPHandler{
 		Logger:   logger,
 		Validate: validate,
 		Usecase:  usecase,
+		DPoP:     dpop,
 	}
 
-	router.HandleFunc("/go-oauth/v1/token", middleware.Verify(handler.TokenRequest)).Methods(http.MethodPost)
+	router.HandleFunc("/go-oauth/v1/token", rateLimit.Verify(middleware.Verify(handler.TokenRequest))).Methods(http.MethodPost)
+	router.HandleFunc("/go-oauth/v1/par", rateLimit.Verify(handler.PushedAuthorization)).Methods(http.MethodPost)
 	router.HandleFunc("/go-oauth/v1/token-verification", handler.TokenVerification).Methods(http.MethodGet)
+	router.HandleFunc("/go-oauth/v1/userinfo", bearerAuth.Verify(handler.UserInfo)).Methods(http.MethodGet, http.MethodPost)
 }
 
 func (handler *HTTPHandler) TokenRequest(w http.ResponseWriter, r *http.Request) {
@@ -49,10 +54,51 @@ func (handler *HTTPHandler) TokenRequest(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if nonce := handler.DPoP.Nonce(); nonce != "" {
+		w.Header().Set(DPoPNonceHeader, nonce)
+	}
+
+	if proof := r.Header.Get(DPoPHeader); proof != "" {
+		jkt, err := handler.DPoP.Verify(r, proof, "")
+		if err != nil {
+			handler.Logger.WithContext(ctx).Warn(err)
+			status, code := response.StatInvalidDPoPProof, ErrInvalidDPoPProof
+			if err == ErrUseDPoPNonce {
+				status, code = response.StatUseDPoPNonce, ErrUseDPoPNonce
+			}
+			resp = response.NewErrorResponse(code, http.StatusBadRequest, nil, status, code.Error())
+			response.JSON(w, resp)
+			return
+		}
+		ctx = context.WithValue(ctx, DPoPThumbprintContextKey{}, jkt)
+	}
+
 	resp = handler.Usecase.RequestToken(ctx, payload)
 	response.JSON(w, resp)
 }
 
+func (handler *HTTPHandler) PushedAuthorization(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.PushedAuthorizationRequest
+	ctx := r.Context()
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp = response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.PushAuthorization(ctx, payload)
+	response.JSON(w, resp)
+}
+
 func (handler *HTTPHandler) TokenVerification(w http.ResponseWriter, r *http.Request) {
 	var resp response.Response
 	queryString := r.URL.Query()
@@ -77,6 +123,11 @@ func (handler *HTTPHandler) TokenVerification(w http.ResponseWriter, r *http.Req
 
 }
 
+func (handler *HTTPHandler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	resp := handler.Usecase.UserInfo(r.Context())
+	response.JSON(w, resp)
+}
+
 func (handler *HTTPHandler) validateRequestBody(body interface{}) (err error) {
 	err = handler.Validate.Struct(body)
 	if err == nil {
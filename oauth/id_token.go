@@ -0,0 +1,147 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+)
+
+const (
+	// BackchannelLogoutEvent event of a logout token (OIDC Back-Channel Logout section 2.4)
+	BackchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+	// LogoutTokenExpiresIn lifetime of a logout token, it is delivered right away
+	LogoutTokenExpiresIn = time.Minute * 2
+)
+
+var errMissingClientSecret = errors.New("openid requires a channel authenticating with a client secret, the key its id tokens are signed with")
+
+// IDTokenClaims claims of an OpenID Connect ID token (OIDC Core section 2)
+type IDTokenClaims struct {
+	Nonce    string   `json:"nonce,omitempty"`
+	AuthTime int64    `json:"auth_time"`
+	AtHash   string   `json:"at_hash,omitempty"`
+	Acr      string   `json:"acr,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+	// SessionID sid of the login session, matched against the logout tokens
+	SessionID string `json:"sid,omitempty"`
+	jwt.StandardClaims
+}
+
+// IDToken sign an ID token for the authenticated end-user. It is signed with
+// HS256 keyed by the client secret so the channel can verify it without
+// sharing the access token key (OIDC Core section 10.1).
+func (a *JWTAccessGenerate) IDToken(ctx context.Context, data *entity.GenerateBasic, accessToken string) (string, error) {
+	if data.TokenInfo.ClientSecret == "" {
+		return "", errMissingClientSecret
+	}
+
+	claims := &IDTokenClaims{
+		Nonce:     data.Nonce,
+		AuthTime:  data.AuthTime.Unix(),
+		AtHash:    accessTokenHash(accessToken),
+		Acr:       data.Acr,
+		AMR:       data.AMR,
+		SessionID: entity.SID(data.SessionID),
+		StandardClaims: jwt.StandardClaims{
+			Audience:  data.ClientId,
+			Issuer:    a.issuer(),
+			IssuedAt:  data.TokenInfo.GetAccessCreateAt().Unix(),
+			Subject:   data.ID,
+			ExpiresAt: data.TokenInfo.GetAccessExpiresAt().Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(data.TokenInfo.ClientSecret))
+}
+
+// LogoutTokenClaims claims of a logout token (OIDC Back-Channel Logout section 2.4)
+type LogoutTokenClaims struct {
+	Events map[string]struct{} `json:"events"`
+	// SessionID sid of the ended session, the one its ID tokens carry
+	SessionID string `json:"sid,omitempty"`
+	jwt.StandardClaims
+}
+
+// LogoutToken sign a logout token telling the channel the end-user session
+// ended, keyed by the client secret like the ID token
+func (a *JWTAccessGenerate) LogoutToken(ctx context.Context, channel entity.Channel, session entity.Session, now time.Time) (string, error) {
+	if channel.SecretKey == "" {
+		return "", errMissingClientSecret
+	}
+
+	claims := &LogoutTokenClaims{
+		Events:    map[string]struct{}{BackchannelLogoutEvent: {}},
+		SessionID: entity.SID(session.ID),
+		StandardClaims: jwt.StandardClaims{
+			Audience:  channel.ClientId,
+			Issuer:    a.issuer(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(LogoutTokenExpiresIn).Unix(),
+			Id:        uuid.NewString(),
+			Subject:   session.UserID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(channel.SecretKey))
+}
+
+// VerifyIDTokenHint verify an ID token this server issued to the channel. An
+// expired token is still a valid hint of who is logging out (OIDC
+// RP-Initiated Logout section 2).
+func (a *JWTAccessGenerate) VerifyIDTokenHint(ctx context.Context, channel entity.Channel, idToken string) (*IDTokenClaims, error) {
+	if channel.SecretKey == "" {
+		return nil, errMissingClientSecret
+	}
+
+	claims := &IDTokenClaims{}
+	_, errParse := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, tokenErr.ErrInvalidIDTokenHint
+		}
+		return []byte(channel.SecretKey), nil
+	})
+	if errParse != nil {
+		validationErr, ok := errParse.(*jwt.ValidationError)
+		if !ok || validationErr.Errors&^jwt.ValidationErrorExpired != 0 {
+			return nil, tokenErr.ErrInvalidIDTokenHint
+		}
+	}
+
+	if claims.Issuer != a.issuer() || claims.Audience != channel.ClientId {
+		return nil, tokenErr.ErrInvalidIDTokenHint
+	}
+
+	return claims, nil
+}
+
+// accessTokenHash left-most half of the SHA-256 of the access token (OIDC Core section 3.1.3.6)
+func accessTokenHash(accessToken string) string {
+	if accessToken == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(accessToken))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// hasScope report whether the scope is part of the granted scopes
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/entity"
+)
+
+func TestIDTokenAcrFollowsTheLoginMethods(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	a := NewJWTAccessGenerate(KeyID(key), key, jwt.SigningMethodHS512)
+
+	tests := []struct {
+		name string
+		amr  []string
+		want string
+	}{
+		{name: "password", amr: []string{entity.AMRPassword}, want: entity.ACRPassword},
+		{name: "second factor", amr: []string{entity.AMRPassword, entity.AMROTP, entity.AMRMFA}, want: entity.ACRMultiFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			// the code carries the acr of the session it was issued in
+			authorization := entity.Authorization{AMR: tt.amr, Acr: entity.ACR(tt.amr)}
+			data := &entity.GenerateBasic{
+				ID:       "user-1",
+				ClientId: "client",
+				AuthTime: now,
+				AMR:      authorization.AMR,
+				Acr:      authorization.Acr,
+				TokenInfo: entity.TokenInfo{
+					ClientSecret:    "client-secret",
+					AccessCreateAt:  now,
+					AccessExpiresAt: now.Add(time.Minute),
+				},
+			}
+
+			idToken, err := a.IDToken(context.Background(), data, "access-token")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims := &IDTokenClaims{}
+			if _, err := jwt.ParseWithClaims(idToken, claims, func(*jwt.Token) (interface{}, error) {
+				return []byte("client-secret"), nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if claims.Acr != tt.want {
+				t.Errorf("acr %q, want %q", claims.Acr, tt.want)
+			}
+		})
+	}
+}
@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	issuer = "https://oauth.github.com"
+	// DefaultIssuer iss claim of the issued tokens when none is configured
+	DefaultIssuer = "https://oauth.github.com"
 )
 
 // JWTAccessClaims jwt claims
@@ -22,15 +23,31 @@ type JWTAccessClaims struct {
 	IsPublic  bool     `json:"isPublic"`
 	Scopes    []string `json:"scopes"`
 	XDeviceId string   `json:"deviceId"`
+	// Act delegation chain of a token obtained through token exchange
+	Act *entity.Actor `json:"act,omitempty"`
+	// Cnf key the token is bound to, the presenter must prove possession of it
+	Cnf *entity.Confirmation `json:"cnf,omitempty"`
+	// AMR factors the end-user authenticated with, resource servers use it to demand step-up
+	AMR []string `json:"amr,omitempty"`
+	// AuthTime time the end-user logged in, only set on tokens of an interactive login
+	AuthTime int64 `json:"auth_time,omitempty"`
 	jwt.StandardClaims
 }
 
-// NewJWTAccessGenerate create to generate the jwt access token instance
+// IsUserLogin report whether the token was issued for an end-user who logged
+// in, the subject of the other tokens is whatever the channel asserted
+func (c *JWTAccessClaims) IsUserLogin() bool {
+	return c.AuthTime != 0
+}
+
+// NewJWTAccessGenerate create to generate the jwt access token instance, its
+// signing key can be rotated with RotateKey
 func NewJWTAccessGenerate(kid string, key []byte, method jwt.SigningMethod) *JWTAccessGenerate {
 	return &JWTAccessGenerate{
 		SignedKeyID:  kid,
 		SignedKey:    key,
 		SignedMethod: method,
+		Keys:         NewKeyRing(SigningKey{ID: kid, Key: key}),
 	}
 }
 
@@ -39,6 +56,46 @@ type JWTAccessGenerate struct {
 	SignedKeyID  string
 	SignedKey    []byte
 	SignedMethod jwt.SigningMethod
+	// Issuer iss claim of the issued tokens, DefaultIssuer when empty
+	Issuer string
+	// Keys rotating signing keys, SignedKeyID and SignedKey are used when nil
+	Keys *KeyRing
+}
+
+// RotateKey sign with the key from now on and keep verifying the tokens signed with the current one
+func (a *JWTAccessGenerate) RotateKey(key SigningKey) {
+	a.Keys.Rotate(key)
+}
+
+// signingKey key the tokens are signed with
+func (a *JWTAccessGenerate) signingKey() SigningKey {
+	if a.Keys == nil {
+		return SigningKey{ID: a.SignedKeyID, Key: a.SignedKey}
+	}
+	return a.Keys.Current()
+}
+
+// verificationKey key of the kid of the token. An unknown kid is checked
+// against the current key, tokens of replicas or releases that named the
+// same key differently stay valid.
+func (a *JWTAccessGenerate) verificationKey(token *jwt.Token) (interface{}, error) {
+	if a.Keys == nil {
+		return a.SignedKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := a.Keys.Lookup(kid)
+	if !ok {
+		key = a.Keys.Current()
+	}
+	return key.Key, nil
+}
+
+func (a *JWTAccessGenerate) issuer() string {
+	if a.Issuer == "" {
+		return DefaultIssuer
+	}
+	return a.Issuer
 }
 
 // Token based on the UUID generated token
@@ -49,21 +106,29 @@ func (a *JWTAccessGenerate) Token(ctx context.Context, data *entity.GenerateBasi
 		IsPublic:  data.IsPublic,
 		IsActive:  data.IsActive,
 		XDeviceId: data.XDeviceId,
+		Act:       data.Act,
+		Cnf:       data.Cnf,
+		AMR:       data.AMR,
 		StandardClaims: jwt.StandardClaims{
 			Audience:  data.Domain,
-			Issuer:    issuer,
+			Issuer:    a.issuer(),
 			IssuedAt:  data.TokenInfo.GetAccessCreateAt().Unix(),
 			Subject:   data.ID,
 			ExpiresAt: data.TokenInfo.GetAccessCreateAt().Add(data.TokenInfo.GetAccessExpiresIn()).Unix(),
 		},
 	}
 
+	if !data.AuthTime.IsZero() {
+		claims.AuthTime = data.AuthTime.Unix()
+	}
+
+	key := a.signingKey()
 	token := jwt.NewWithClaims(a.SignedMethod, claims)
-	if a.SignedKeyID != "" {
-		token.Header["kid"] = a.SignedKeyID
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
 	}
 
-	access, err := token.SignedString(a.SignedKey)
+	access, err := token.SignedString(key.Key)
 	if err != nil {
 		return "", "", err
 	}
@@ -79,11 +144,9 @@ func (a *JWTAccessGenerate) Token(ctx context.Context, data *entity.GenerateBasi
 }
 
 func (a *JWTAccessGenerate) Verify(ctx context.Context, accessToken string) (*JWTAccessClaims, error) {
-	token, errParse := jwt.ParseWithClaims(accessToken, &JWTAccessClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(a.SignedKey), nil
-	})
+	token, errParse := jwt.ParseWithClaims(accessToken, &JWTAccessClaims{}, a.verificationKey)
 
-	if token.Method != a.SignedMethod {
+	if token == nil || token.Method != a.SignedMethod {
 		return nil, err.ErrInvalidAccessToken
 	}
 
@@ -113,7 +176,7 @@ func (a *JWTAccessGenerate) Verify(ctx context.Context, accessToken string) (*JW
 
 	// Check if the token claims are valid and the token itself is valid
 	if claims, ok := token.Claims.(*JWTAccessClaims); ok && token.Valid {
-		if claims.Issuer != issuer {
+		if claims.Issuer != a.issuer() {
 			return nil, err.ErrValidationIssuer
 		}
 
@@ -122,3 +185,21 @@ func (a *JWTAccessGenerate) Verify(ctx context.Context, accessToken string) (*JW
 		return nil, err.ErrInvalidAccessToken
 	}
 }
+
+// CheckSigningKey sign and verify a probe token, tokens can't be issued until it succeeds
+func (a *JWTAccessGenerate) CheckSigningKey(ctx context.Context) error {
+	key := a.signingKey()
+	if len(key.Key) == 0 || a.SignedMethod == nil {
+		return err.ErrSigningKeyMissing
+	}
+
+	probe := jwt.NewWithClaims(a.SignedMethod, jwt.StandardClaims{Issuer: a.issuer()})
+	probe.Header["kid"] = key.ID
+	signed, errSign := probe.SignedString(key.Key)
+	if errSign != nil {
+		return errSign
+	}
+
+	_, errVerify := jwt.Parse(signed, a.verificationKey)
+	return errVerify
+}
@@ -0,0 +1,44 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// jwtBearer issue a token for the subject of an assertion signed by the channel (RFC 7523 section 2.1)
+func (u *usecase) jwtBearer(ctx context.Context, channel entity.Channel, payload model.TokenRequest) response.Response {
+	claims, err := u.clientAuthenticator.VerifyAssertion(ctx, channel, payload.Assertion)
+	if err != nil {
+		u.logger.WithContext(ctx).Warn(err)
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidGrant.Error())
+	}
+
+	if claims.Issuer != channel.ClientId || claims.Subject == "" {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidGrant.Error())
+	}
+
+	scope := payload.Scope
+	if scope == "" {
+		scope = claims.Scope
+	}
+
+	scopes := channel.Scopes
+	if scope != "" {
+		scopes = strings.Fields(scope)
+	}
+
+	if !channel.HasScopes(scopes) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, tokenErr.ErrInvalidScope.Error())
+	}
+
+	data := u.generateBasic(ctx, channel, scopes)
+	data.ID = claims.Subject
+
+	return u.issueToken(ctx, data)
+}
@@ -0,0 +1,80 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"sync/atomic"
+)
+
+// SigningKey key the access tokens are signed with, identified by the kid header
+type SigningKey struct {
+	ID  string
+	Key []byte
+}
+
+// KeyRing signing key of the access tokens and the previous one, still
+// accepted so tokens issued before a rotation stay valid until they expire.
+// Copies of a JWTAccessGenerate share it, a rotation is seen by all of them.
+type KeyRing struct {
+	keys atomic.Pointer[keySet]
+}
+
+type keySet struct {
+	current  SigningKey
+	previous *SigningKey
+}
+
+// NewKeyRing is a constructor.
+func NewKeyRing(key SigningKey) *KeyRing {
+	ring := new(KeyRing)
+	ring.keys.Store(&keySet{current: key})
+	return ring
+}
+
+// Rotate sign with the key from now on, the current one is kept for verification
+// only and the one before it is dropped. Rotating to the current key does nothing.
+func (r *KeyRing) Rotate(key SigningKey) {
+	for {
+		old := r.keys.Load()
+		if old.current.ID == key.ID {
+			return
+		}
+
+		previous := old.current
+		if r.keys.CompareAndSwap(old, &keySet{current: key, previous: &previous}) {
+			return
+		}
+	}
+}
+
+// Current key the tokens are signed with
+func (r *KeyRing) Current() SigningKey {
+	return r.keys.Load().current
+}
+
+// Lookup key of the kid, the current one when the token has no kid
+func (r *KeyRing) Lookup(kid string) (SigningKey, bool) {
+	keys := r.keys.Load()
+	switch {
+	case kid == "" || kid == keys.current.ID:
+		return keys.current, true
+	case keys.previous != nil && kid == keys.previous.ID:
+		return *keys.previous, true
+	}
+	return SigningKey{}, false
+}
+
+// Keys current key then the previous one, the keys a token or a MAC may have been made with
+func (r *KeyRing) Keys() []SigningKey {
+	keys := r.keys.Load()
+	if keys.previous == nil {
+		return []SigningKey{keys.current}
+	}
+	return []SigningKey{keys.current, *keys.previous}
+}
+
+// KeyID kid of a key derived from its content, replicas sharing the key agree on it
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/entity"
+)
+
+func signTestToken(t *testing.T, a *JWTAccessGenerate) string {
+	now := time.Now()
+	access, _, err := a.Token(context.Background(), &entity.GenerateBasic{
+		ClientId: "client",
+		TokenInfo: entity.TokenInfo{
+			AccessCreateAt:  now,
+			AccessExpiresIn: time.Minute,
+		},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return access
+}
+
+func TestRotateKeyVerifiesTheTokensOfThePreviousKey(t *testing.T) {
+	ctx := context.Background()
+	first, second, third := []byte(strings.Repeat("1", 32)), []byte(strings.Repeat("2", 32)), []byte(strings.Repeat("3", 32))
+	a := NewJWTAccessGenerate(KeyID(first), first, jwt.SigningMethodHS512)
+
+	beforeRotation := signTestToken(t, a)
+	a.RotateKey(SigningKey{ID: KeyID(second), Key: second})
+	afterRotation := signTestToken(t, a)
+
+	for name, token := range map[string]string{"previous key": beforeRotation, "current key": afterRotation} {
+		if claims, err := a.Verify(ctx, token); err != nil || claims.ClientId != "client" {
+			t.Errorf("token of the %s: claims %v, err %v", name, claims, err)
+		}
+	}
+
+	// a copy shares the ring, as the token authenticator does
+	if _, err := (*a).Verify(ctx, beforeRotation); err != nil {
+		t.Errorf("copy doesn't verify the token of the previous key: %v", err)
+	}
+
+	// only one previous key is kept
+	a.RotateKey(SigningKey{ID: KeyID(third), Key: third})
+	if _, err := a.Verify(ctx, beforeRotation); err == nil {
+		t.Error("token of a key rotated out twice still verifies")
+	}
+	if _, err := a.Verify(ctx, afterRotation); err != nil {
+		t.Errorf("token of the previous key: %v", err)
+	}
+}
+
+func TestRotateNonceKeyKeepsTheIssuedNonces(t *testing.T) {
+	first, second, third := []byte(strings.Repeat("1", 32)), []byte(strings.Repeat("2", 32)), []byte(strings.Repeat("3", 32))
+	v := NewDPoPVerifier("https://oauth.example.com", nil, first, true)
+
+	beforeRotation := v.Nonce()
+	v.RotateNonceKey(second)
+	afterRotation := v.Nonce()
+
+	if !v.validNonce(beforeRotation) || !v.validNonce(afterRotation) {
+		t.Errorf("nonces before %v and after %v the rotation, want both valid", v.validNonce(beforeRotation), v.validNonce(afterRotation))
+	}
+
+	// a replica that didn't rotate yet refuses the nonces of the new key
+	if NewDPoPVerifier("https://oauth.example.com", nil, first, true).validNonce(afterRotation) {
+		t.Error("nonce of the new key valid with the old key only")
+	}
+
+	v.RotateNonceKey(third)
+	if v.validNonce(beforeRotation) {
+		t.Error("nonce of a key rotated out twice still valid")
+	}
+}
@@ -0,0 +1,238 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+)
+
+const (
+	pushAuthorizationSuccessMessage = "Push Authorization Request Successfully"
+	errorPushAuthorizationMessage   = "Push Authorization Request Failed!"
+
+	// PushedAuthorizationExpiresIn lifetime of a request_uri
+	PushedAuthorizationExpiresIn = time.Second * 90
+)
+
+// PushAuthorization store the authorization request of an authenticated
+// channel and return the request_uri referencing it (RFC 9126 section 2)
+func (u *usecase) PushAuthorization(ctx context.Context, payload model.PushedAuthorizationRequest) response.Response {
+	ctx, span := tracing.Start(ctx, "oauth.PushAuthorization")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, payload.ClientId)
+
+	now := time.Now().In(u.loc)
+
+	channel, err := u.clientAuthenticator.Authenticate(ctx, payload.ClientAuthentication)
+	if err != nil {
+		if err == tokenErr.ErrInvalidClient {
+			entity.SetInvalidClientInContext(ctx)
+		}
+		if err == exception.ErrNotFound || err == tokenErr.ErrInvalidClient {
+			return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorPushAuthorizationMessage)
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	if !channel.IsActive || !channel.HasGrantType(entity.AuthorizationCode) {
+		return response.NewErrorResponse(tokenErr.ErrUnauthorizedClient, http.StatusBadRequest, nil, response.StatBadRequest, tokenErr.ErrUnauthorizedClient.Error())
+	}
+
+	signed := false
+	if payload.Request != "" {
+		claims, err := u.clientAuthenticator.VerifyRequestObject(ctx, channel, payload.Request)
+		if err != nil {
+			u.logger.WithContext(ctx).Warn(err)
+			return response.NewErrorResponse(tokenErr.ErrInvalidRequestObject, http.StatusBadRequest, nil, response.StatInvalidRequestObject, tokenErr.ErrInvalidRequestObject.Error())
+		}
+		// the pushed request is redeemed once, so is the object it carries
+		if err := u.clientAuthenticator.ConsumeRequestObject(ctx, channel.ClientId, claims.ID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			if err != tokenErr.ErrInvalidRequestObject {
+				return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+			}
+			return response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatInvalidRequestObject, err.Error())
+		}
+		request := mergeRequestObject(model.AuthorizeRequest{}, claims)
+		payload.ResponseType = request.ResponseType
+		payload.RedirectURI = request.RedirectURI
+		payload.Scope = request.Scope
+		payload.State = request.State
+		payload.Nonce = request.Nonce
+		payload.CodeChallenge = request.CodeChallenge
+		payload.CodeChallengeMethod = request.CodeChallengeMethod
+		signed = true
+	}
+
+	if !channel.HasRedirectURI(payload.RedirectURI) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatBadRequest, tokenErr.ErrInvalidRedirectURI.Error())
+	}
+
+	if payload.ResponseType != responseTypeCode {
+		return response.NewErrorResponse(tokenErr.ErrUnsupportedResponseType, http.StatusBadRequest, nil, response.StatBadRequest, tokenErr.ErrUnsupportedResponseType.Error())
+	}
+
+	if !channel.HasScopes(RequestedScopes(channel, payload.Scope)) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, tokenErr.ErrInvalidScope.Error())
+	}
+
+	reference, err := generateAuthorizationCode()
+	if err != nil {
+		u.logger.WithContext(ctx).Error(err)
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorPushAuthorizationMessage)
+	}
+
+	pushed := entity.PushedAuthorization{
+		ID:                  uuid.NewString(),
+		RequestURI:          entity.RequestURIPrefix + reference,
+		ClientId:            channel.ClientId,
+		ResponseType:        payload.ResponseType,
+		RedirectURI:         payload.RedirectURI,
+		Scope:               payload.Scope,
+		State:               payload.State,
+		Nonce:               payload.Nonce,
+		CodeChallenge:       payload.CodeChallenge,
+		CodeChallengeMethod: payload.CodeChallengeMethod,
+		Signed:              signed,
+		ExpiresAt:           now.Add(PushedAuthorizationExpiresIn),
+		CreatedAt:           now,
+	}
+
+	if err := u.pushedAuthorizationRepository.InsertOne(ctx, pushed); err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorPushAuthorizationMessage)
+	}
+
+	data := model.PushedAuthorizationResponse{
+		RequestURI: pushed.RequestURI,
+		ExpiresIn:  int64(PushedAuthorizationExpiresIn.Seconds()),
+	}
+
+	return response.NewSuccessResponse(data, response.StatCreated, pushAuthorizationSuccessMessage)
+}
+
+// ResolveAuthorizeRequest replace the parameters of an authorization request
+// by those of the pushed request or of the signed request object it carries.
+// A pushed request_uri stays valid until a code is issued so the login and
+// consent steps can reuse it.
+func (u *usecase) ResolveAuthorizeRequest(ctx context.Context, payload model.AuthorizeRequest) (model.AuthorizeRequest, error) {
+	ctx, span := tracing.Start(ctx, "oauth.ResolveAuthorizeRequest")
+	defer span.End()
+
+	if payload.Request != "" && payload.RequestURI != "" {
+		return payload, tokenErr.ErrInvalidRequestObject
+	}
+
+	if isPushedRequestURI(payload.RequestURI) {
+		return u.resolvePushedAuthorization(ctx, payload)
+	}
+
+	if payload.Request == "" && payload.RequestURI == "" {
+		return payload, nil
+	}
+
+	channel, err := u.channelRepository.FindByClientId(ctx, payload.ClientId)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			err = tokenErr.ErrUnauthorizedClient
+		}
+		return payload, err
+	}
+
+	request := payload.Request
+	if payload.RequestURI != "" {
+		if request, err = u.clientAuthenticator.FetchRequestObject(ctx, channel, payload.RequestURI); err != nil {
+			u.logger.WithContext(ctx).Warn(err)
+			return payload, tokenErr.ErrInvalidRequestURI
+		}
+	}
+
+	claims, err := u.clientAuthenticator.VerifyRequestObject(ctx, channel, request)
+	if err != nil {
+		u.logger.WithContext(ctx).Warn(err)
+		return payload, tokenErr.ErrInvalidRequestObject
+	}
+
+	return mergeRequestObject(payload, claims), nil
+}
+
+// mergeRequestObject only the parameters of the request object are used, the
+// ones sent along it are ignored even when the object lacks them (RFC 9101
+// section 6.3). The client id and the reference to the object are kept.
+func mergeRequestObject(payload model.AuthorizeRequest, claims *channel.RequestObjectClaims) model.AuthorizeRequest {
+	return model.AuthorizeRequest{
+		ResponseType:           claims.ResponseType,
+		ClientId:               payload.ClientId,
+		RedirectURI:            claims.RedirectURI,
+		Scope:                  claims.Scope,
+		State:                  claims.State,
+		Nonce:                  claims.Nonce,
+		CodeChallenge:          claims.CodeChallenge,
+		CodeChallengeMethod:    claims.CodeChallengeMethod,
+		RequestURI:             payload.RequestURI,
+		Request:                payload.Request,
+		Signed:                 true,
+		RequestObjectID:        claims.ID,
+		RequestObjectExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}
+}
+
+func (u *usecase) resolvePushedAuthorization(ctx context.Context, payload model.AuthorizeRequest) (model.AuthorizeRequest, error) {
+	pushed, err := u.pushedAuthorizationRepository.FindByRequestURI(ctx, payload.RequestURI)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			err = tokenErr.ErrInvalidRequestURI
+		}
+		return payload, err
+	}
+
+	if pushed.ClientId != payload.ClientId || pushed.IsExpired(time.Now().In(u.loc)) {
+		return payload, tokenErr.ErrInvalidRequestURI
+	}
+
+	return model.AuthorizeRequest{
+		ResponseType:        pushed.ResponseType,
+		ClientId:            pushed.ClientId,
+		RedirectURI:         pushed.RedirectURI,
+		Scope:               pushed.Scope,
+		State:               pushed.State,
+		Nonce:               pushed.Nonce,
+		CodeChallenge:       pushed.CodeChallenge,
+		CodeChallengeMethod: pushed.CodeChallengeMethod,
+		RequestURI:          pushed.RequestURI,
+		Signed:              pushed.Signed,
+	}, nil
+}
+
+func isPushedRequestURI(requestURI string) bool {
+	return strings.HasPrefix(requestURI, entity.RequestURIPrefix)
+}
+
+// consumePushedAuthorization a request_uri is redeemed once, with the code it produced
+func (u *usecase) consumePushedAuthorization(ctx context.Context, requestURI string) error {
+	pushed, err := u.pushedAuthorizationRepository.FindByRequestURI(ctx, requestURI)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return tokenErr.ErrInvalidRequestURI
+		}
+		return err
+	}
+
+	if err := u.pushedAuthorizationRepository.DeleteOne(ctx, pushed.ID); err != nil {
+		if err == exception.ErrNotFound {
+			return tokenErr.ErrInvalidRequestURI
+		}
+		return err
+	}
+
+	return nil
+}
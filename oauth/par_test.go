@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+)
+
+type rejectingAuthenticator struct {
+	channel.ClientAuthenticator
+	err error
+}
+
+func (a rejectingAuthenticator) Authenticate(ctx context.Context, credentials model.ClientAuthentication) (entity.Channel, error) {
+	return entity.Channel{}, a.err
+}
+
+func TestPushAuthorizationFlagsOnlyRejectedCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		flagged bool
+	}{
+		{name: "rejected credentials", err: tokenErr.ErrInvalidClient, flagged: true},
+		{name: "unknown client", err: exception.ErrNotFound, flagged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &usecase{clientAuthenticator: rejectingAuthenticator{err: tt.err}, loc: time.UTC}
+			ctx := context.WithValue(context.Background(), entity.RequestContextKey{}, new(entity.Request))
+
+			u.PushAuthorization(ctx, model.PushedAuthorizationRequest{ClientAuthentication: model.ClientAuthentication{ClientId: "client"}})
+
+			if got := entity.IsInvalidClientInContext(ctx); got != tt.flagged {
+				t.Errorf("invalid client flag %v, want %v", got, tt.flagged)
+			}
+		})
+	}
+}
+
+func TestMergeRequestObjectUsesOnlyItsParameters(t *testing.T) {
+	payload := model.AuthorizeRequest{
+		ResponseType: "code",
+		ClientId:     "client",
+		RedirectURI:  "https://attacker.example/cb",
+		Scope:        "openid profile",
+		State:        "query-state",
+		Request:      "signed.request.object",
+	}
+	claims := &channel.RequestObjectClaims{
+		ID:           "jti-1",
+		ExpiresAt:    time.Now().Add(time.Minute).Unix(),
+		ResponseType: "code",
+		RedirectURI:  "https://client.example/cb",
+		Scope:        "openid",
+	}
+
+	got := mergeRequestObject(payload, claims)
+
+	if got.RedirectURI != claims.RedirectURI || got.Scope != claims.Scope {
+		t.Errorf("redirect_uri %q scope %q, want those of the request object", got.RedirectURI, got.Scope)
+	}
+	if got.State != "" {
+		t.Errorf("state %q taken from outside the request object", got.State)
+	}
+	if got.ClientId != payload.ClientId || got.Request != payload.Request {
+		t.Errorf("client id or request object reference lost")
+	}
+	if !got.Signed || got.RequestObjectID != claims.ID || got.RequestObjectExpiresAt.Unix() != claims.ExpiresAt {
+		t.Errorf("request object not recorded: %+v", got)
+	}
+}
@@ -5,12 +5,32 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/consent"
+	"github.com/umerthow/go-oauth/device"
 )
 
 type UsecaseOauthProperty struct {
-	ServiceName        string
-	Logger             *logrus.Logger
-	Location           *time.Location
-	ChannelsRepository channel.ChannelsRepository
-	JWT                JWTAccessGenerate
+	ServiceName                   string
+	Logger                        *logrus.Logger
+	Location                      *time.Location
+	ClientAuthenticator           channel.ClientAuthenticator
+	ChannelsRepository            channel.ChannelsRepository
+	AuthorizationRepository       AuthorizationsRepository
+	PushedAuthorizationRepository PushedAuthorizationsRepository
+	RefreshTokenRepository        RefreshTokensRepository
+	DeviceRepository              device.DeviceRepository
+	ConsentRepository             consent.ConsentsRepository
+	// UserInfoFinder optional, without it userinfo only releases the subject
+	UserInfoFinder UserInfoFinder
+	// UserStatusChecker optional, without it refresh tokens of disabled or locked users are still redeemed
+	UserStatusChecker UserStatusChecker
+	// UsageMeter optional, without it issued tokens are neither counted nor limited
+	UsageMeter UsageMeter
+	// AuditRecorder optional, records the tokens issued, refused and revoked
+	AuditRecorder AuditRecorder
+	JWT           JWTAccessGenerate
+	// AccessTokenExpiresIn lifetime of the access tokens, DefaultAccessTokenExpiresIn when zero
+	AccessTokenExpiresIn time.Duration
+	// RefreshTokenExpiresIn lifetime of the refresh tokens, DefaultRefreshTokenExpiresIn when zero
+	RefreshTokenExpiresIn time.Duration
 }
@@ -0,0 +1,74 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PushedAuthorizationsRepository interface {
+	InsertOne(ctx context.Context, entryData entity.PushedAuthorization) (err error)
+	FindByRequestURI(ctx context.Context, requestURI string) (pushed entity.PushedAuthorization, err error)
+	DeleteOne(ctx context.Context, id string) (err error)
+	// EnsureIndexes create the index removing the requests once they expire
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type pushedAuthorizationRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewPushedAuthorizationRepository(logger *logrus.Logger, db mongodb.Database) PushedAuthorizationsRepository {
+	col := db.Collection("oauth_pushed_authorization")
+	return &pushedAuthorizationRepository{logger, col}
+}
+
+func (r *pushedAuthorizationRepository) InsertOne(ctx context.Context, entryData entity.PushedAuthorization) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *pushedAuthorizationRepository) FindByRequestURI(ctx context.Context, requestURI string) (pushed entity.PushedAuthorization, err error) {
+	if err = r.col.FindOne(ctx, bson.M{"request_uri": requestURI}).Decode(&pushed); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *pushedAuthorizationRepository) DeleteOne(ctx context.Context, id string) (err error) {
+	resp, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *pushedAuthorizationRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.TTLIndex("expires_at", 0)); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// DefaultRefreshTokenExpiresIn lifetime of a refresh token, rotation keeps the
+// expiry of the first one so a login can't be extended forever
+const DefaultRefreshTokenExpiresIn = time.Hour * 24 * 30
+
+// refreshToken redeem a refresh token, it is rotated on every use (RFC 6749 section 6)
+func (u *usecase) refreshToken(ctx context.Context, channel entity.Channel, payload model.TokenRequest) response.Response {
+	now := time.Now().In(u.loc)
+
+	refreshToken, err := u.refreshTokenRepository.FindByTokenHash(ctx, hashRefreshToken(payload.RefreshToken))
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidRefreshToken.Error())
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	// consume the token first, a concurrent redemption finds it gone
+	if err := u.refreshTokenRepository.DeleteOne(ctx, refreshToken.ID); err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidRefreshToken.Error())
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	if refreshToken.ClientId != channel.ClientId {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidRefreshToken.Error())
+	}
+
+	if refreshToken.IsExpired(now) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrExpiredRefreshToken.Error())
+	}
+
+	// a user disabled or locked since the login can't keep refreshing
+	if u.userStatusChecker != nil {
+		active, err := u.userStatusChecker.IsUserActive(ctx, refreshToken.UserID)
+		if err != nil {
+			return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		}
+		if !active {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidRefreshToken.Error())
+		}
+	}
+
+	// the scope can only be narrowed (RFC 6749 section 6)
+	scopes := refreshToken.Scopes
+	if payload.Scope != "" {
+		scopes = strings.Fields(payload.Scope)
+		granted := entity.Channel{Scopes: refreshToken.Scopes}
+		if !granted.HasScopes(scopes) {
+			return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, tokenErr.ErrInvalidScope.Error())
+		}
+	}
+
+	data := u.generateBasic(ctx, channel, scopes)
+	data.ID = refreshToken.UserID
+	data.AuthTime = refreshToken.AuthTime
+	data.AMR = refreshToken.AMR
+	data.Acr = refreshToken.Acr
+	data.SessionID = refreshToken.SessionID
+	data.TokenInfo.RefreshExpiresAt = refreshToken.ExpiresAt
+
+	return u.issueToken(ctx, data)
+}
+
+// storeRefreshToken persist the hash of a refresh token bound to the session of the login
+func (u *usecase) storeRefreshToken(ctx context.Context, data *entity.GenerateBasic, token string) error {
+	now := time.Now().In(u.loc)
+
+	expiresAt := data.TokenInfo.RefreshExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(u.refreshTokenExpiresIn)
+	}
+
+	return u.refreshTokenRepository.InsertOne(ctx, entity.RefreshToken{
+		ID:        uuid.NewString(),
+		TokenHash: hashRefreshToken(token),
+		ClientId:  data.ClientId,
+		UserID:    data.ID,
+		SessionID: data.SessionID,
+		Scopes:    data.Scopes,
+		AuthTime:  data.AuthTime,
+		AMR:       data.AMR,
+		Acr:       data.Acr,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	})
+}
+
+// isRefreshable only logins tied to a browser session get a refresh token,
+// ending the session must be able to revoke it
+func isRefreshable(data *entity.GenerateBasic) bool {
+	if data.SessionID == "" {
+		return false
+	}
+
+	channel := entity.Channel{GrantTypes: data.GrantTypes}
+
+	return channel.HasGrantType(entity.Refreshing)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RefreshTokensRepository interface {
+	InsertOne(ctx context.Context, entryData entity.RefreshToken) (err error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (refreshToken entity.RefreshToken, err error)
+	DeleteOne(ctx context.Context, id string) (err error)
+	// DeleteBySessionID revoke every refresh token issued during the session
+	DeleteBySessionID(ctx context.Context, sessionID string) (deleted int64, err error)
+	// EnsureIndexes create the index removing the tokens once they expire
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type refreshTokenRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewRefreshTokenRepository(logger *logrus.Logger, db mongodb.Database) RefreshTokensRepository {
+	col := db.Collection("oauth_refresh_token")
+	return &refreshTokenRepository{logger, col}
+}
+
+func (r *refreshTokenRepository) InsertOne(ctx context.Context, entryData entity.RefreshToken) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *refreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (refreshToken entity.RefreshToken, err error) {
+	if err = r.col.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&refreshToken); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *refreshTokenRepository) DeleteOne(ctx context.Context, id string) (err error) {
+	resp, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *refreshTokenRepository) DeleteBySessionID(ctx context.Context, sessionID string) (deleted int64, err error) {
+	resp, err := r.col.DeleteMany(ctx, bson.M{"session_id": sessionID})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	return resp.DeletedCount, nil
+}
+
+func (r *refreshTokenRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.TTLIndex("expires_at", 0)); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
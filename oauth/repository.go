@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AuthorizationsRepository interface {
+	InsertOne(ctx context.Context, entryData entity.Authorization) (err error)
+	FindByCode(ctx context.Context, code string) (authorization entity.Authorization, err error)
+	// DeleteOne return exception.ErrNotFound when the code was already deleted, so it is redeemed once
+	DeleteOne(ctx context.Context, id string) (err error)
+	// EnsureIndexes create the index removing the codes once they expire
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type authorizationRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewAuthorizationRepository(logger *logrus.Logger, db mongodb.Database) AuthorizationsRepository {
+	col := db.Collection("oauth_authorization_code")
+	return &authorizationRepository{logger, col}
+}
+
+func (r *authorizationRepository) InsertOne(ctx context.Context, entryData entity.Authorization) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *authorizationRepository) FindByCode(ctx context.Context, code string) (authorization entity.Authorization, err error) {
+	if err = r.col.FindOne(ctx, bson.M{"code": code}).Decode(&authorization); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *authorizationRepository) DeleteOne(ctx context.Context, id string) (err error) {
+	resp, err := r.col.DeleteOne(ctx, bson.M{"id": id})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *authorizationRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.TTLIndex("expires_at", 0)); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"html/template"
+
+	"github.com/umerthow/go-oauth/model"
+)
+
+type consentPage struct {
+	ChannelName string
+	Scopes      []model.ConsentScope
+	Request     model.AuthorizeRequest
+	CSRFToken   string
+}
+
+var consentTemplate = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Authorize {{.ChannelName}}</title>
+</head>
+<body>
+	<h1>{{.ChannelName}} wants to access your account</h1>
+	<p>This will allow {{.ChannelName}} to:</p>
+	<ul>
+		{{range .Scopes}}<li>{{.Description}}</li>{{end}}
+	</ul>
+	<form method="POST">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+		<input type="hidden" name="client_id" value="{{.Request.ClientId}}">
+		<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+		<input type="hidden" name="scope" value="{{.Request.Scope}}">
+		<input type="hidden" name="state" value="{{.Request.State}}">
+		<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+		<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+		<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+		<input type="hidden" name="request_uri" value="{{.Request.RequestURI}}">
+		<input type="hidden" name="request" value="{{.Request.Request}}">
+		<button type="submit" name="action" value="approve">Allow</button>
+		<button type="submit" name="action" value="deny">Deny</button>
+	</form>
+</body>
+</html>
+`))
+
+type logoutPage struct {
+	LoggedOut bool
+	Request   model.EndSessionRequest
+	CSRFToken string
+}
+
+var logoutTemplate = template.Must(template.New("logout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Sign out</title>
+</head>
+<body>
+	{{if .LoggedOut}}
+	<h1>You have been signed out</h1>
+	{{else}}
+	<h1>Do you want to sign out?</h1>
+	<form method="POST" action="/go-oauth/v1/end_session">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<input type="hidden" name="id_token_hint" value="{{.Request.IDTokenHint}}">
+		<input type="hidden" name="client_id" value="{{.Request.ClientId}}">
+		<input type="hidden" name="post_logout_redirect_uri" value="{{.Request.PostLogoutRedirectURI}}">
+		<input type="hidden" name="state" value="{{.Request.State}}">
+		<button type="submit">Sign out</button>
+	</form>
+	{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,107 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/middleware"
+)
+
+type ClaimsContextKey struct{}
+
+// GetClaimsFromContext claims of the access token authenticated by the bearer middleware
+func GetClaimsFromContext(ctx context.Context) *JWTAccessClaims {
+	claims, _ := ctx.Value(ClaimsContextKey{}).(*JWTAccessClaims)
+
+	return claims
+}
+
+type tokenAuthenticator struct {
+	jwt  JWTAccessGenerate
+	dpop *DPoPVerifier
+}
+
+// NewTokenAuthenticator verify access tokens for the bearer middleware
+func NewTokenAuthenticator(jwt JWTAccessGenerate, dpop *DPoPVerifier) middleware.TokenAuthenticator {
+	return &tokenAuthenticator{jwt, dpop}
+}
+
+// AuthenticateToken verify the signature and that the presenter holds the key the token is bound to
+func (a *tokenAuthenticator) AuthenticateToken(r *http.Request, token string) (context.Context, error) {
+	ctx := r.Context()
+
+	claims, err := a.jwt.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyConfirmation(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	if err := a.verifyDPoP(r, claims, token); err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, ClaimsContextKey{}, claims)
+	// only an end-user who logged in is a subject whose data can be managed
+	if claims.IsUserLogin() {
+		ctx = context.WithValue(ctx, entity.SubjectContextKey{}, claims.Subject)
+	}
+	ctx = context.WithValue(ctx, entity.ScopesContextKey{}, claims.Scopes)
+	ctx = context.WithValue(ctx, entity.ActorContextKey{}, claims.Subject)
+	entity.SetClientIdInContext(ctx, claims.ClientId)
+
+	return ctx, nil
+}
+
+// verifyDPoP require a proof signed by the key a DPoP token is bound to (RFC 9449 section 7)
+func (a *tokenAuthenticator) verifyDPoP(r *http.Request, claims *JWTAccessClaims, token string) error {
+	scheme, _, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	isBound := claims.Cnf != nil && claims.Cnf.JKT != ""
+
+	if !isBound {
+		// a DPoP scheme with an unbound token is a downgrade attempt
+		if strings.EqualFold(scheme, DPoPHeader) {
+			return tokenErr.ErrInvalidAccessToken
+		}
+		return nil
+	}
+
+	if !strings.EqualFold(scheme, DPoPHeader) {
+		return tokenErr.ErrInvalidAccessToken
+	}
+
+	jkt, err := a.dpop.Verify(r, r.Header.Get(DPoPHeader), token)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(jkt), []byte(claims.Cnf.JKT)) != 1 {
+		return errDPoPProofBinding
+	}
+
+	return nil
+}
+
+// verifyConfirmation require the certificate a token is bound to (RFC 8705 section 3)
+func verifyConfirmation(ctx context.Context, claims *JWTAccessClaims) error {
+	if claims.Cnf == nil || claims.Cnf.X5tS256 == "" {
+		return nil
+	}
+
+	cert := entity.GetCertificateFromContext(ctx)
+	if cert == nil {
+		return tokenErr.ErrInvalidAccessToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(entity.CertificateThumbprint(cert)), []byte(claims.Cnf.X5tS256)) != 1 {
+		return tokenErr.ErrInvalidAccessToken
+	}
+
+	return nil
+}
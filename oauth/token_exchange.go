@@ -0,0 +1,91 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+// tokenExchange trade a subject token for a narrower token bound to the requested audience (RFC 8693)
+func (u *usecase) tokenExchange(ctx context.Context, channel entity.Channel, payload model.TokenRequest) response.Response {
+	if !isSupportedTokenType(payload.SubjectTokenType) || (payload.ActorToken != "" && !isSupportedTokenType(payload.ActorTokenType)) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatBadRequest, tokenErr.ErrInvalidRequest.Error())
+	}
+
+	// an unsupported requested_token_type is an invalid request (RFC 8693 section 2.2.2)
+	if payload.RequestedTokenType != "" && !isSupportedTokenType(payload.RequestedTokenType) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatBadRequest, tokenErr.ErrInvalidRequest.Error())
+	}
+
+	subject, err := u.jwt.Verify(ctx, payload.SubjectToken)
+	if err != nil {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, err.Error())
+	}
+	// the holder of a bound token isn't proven here, exchanging it would
+	// hand whoever stole it a token free of the binding
+	if subject.Cnf != nil {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, errorBoundTokenExchangeMessage)
+	}
+
+	// the requesting channel is the actor, it follows the prior actors of the subject token
+	actor := &entity.Actor{
+		Subject:  channel.ID,
+		ClientId: channel.ClientId,
+		Act:      subject.Act,
+	}
+	// another party proving it is acting becomes the current actor, the requesting channel stays in the chain
+	if payload.ActorToken != "" {
+		actorClaims, err := u.jwt.Verify(ctx, payload.ActorToken)
+		if err != nil {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, err.Error())
+		}
+		if actorClaims.Cnf != nil {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, errorBoundTokenExchangeMessage)
+		}
+		if actorClaims.ClientId != channel.ClientId || actorClaims.Subject != channel.ID {
+			actor = &entity.Actor{
+				Subject:  actorClaims.Subject,
+				ClientId: actorClaims.ClientId,
+				Act:      actor,
+			}
+		}
+	}
+
+	policy := channel.ExchangePolicy
+
+	audience := payload.Audience
+	if audience == "" {
+		audience = subject.Audience
+	}
+	if !policy.AllowsAudience(audience) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidTarget, http.StatusBadRequest, nil, response.StatInvalidTarget, tokenErr.ErrInvalidTarget.Error())
+	}
+
+	// an exchanged token can only narrow the subject token scopes
+	scopes := subject.Scopes
+	if payload.Scope != "" {
+		scopes = strings.Fields(payload.Scope)
+	}
+	subjectChannel := entity.Channel{Scopes: subject.Scopes}
+	if !subjectChannel.HasScopes(scopes) || !policy.AllowsScopes(scopes) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, tokenErr.ErrInvalidScope.Error())
+	}
+
+	data := u.generateBasic(ctx, channel, scopes)
+	data.ID = subject.Subject
+	data.Domain = audience
+	data.Act = actor
+
+	data.IssuedTokenType = entity.TokenTypeAccessToken
+
+	return u.issueToken(ctx, data)
+}
+
+func isSupportedTokenType(tokenType string) bool {
+	return tokenType == entity.TokenTypeAccessToken || tokenType == entity.TokenTypeJWT
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+func TestTokenExchangeRefusesBoundTokens(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	u := &usecase{jwt: *NewJWTAccessGenerate(KeyID(key), key, jwt.SigningMethodHS512)}
+	issue := func(cnf *entity.Confirmation) string {
+		access, _, err := u.jwt.Token(context.Background(), &entity.GenerateBasic{
+			ClientId:  "holder",
+			Scopes:    []string{"read"},
+			Cnf:       cnf,
+			TokenInfo: entity.TokenInfo{AccessCreateAt: time.Now(), AccessExpiresIn: time.Minute},
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return access
+	}
+
+	unbound := issue(nil)
+	tests := []struct {
+		name         string
+		subjectToken string
+		actorToken   string
+	}{
+		{name: "dpop bound subject token", subjectToken: issue(&entity.Confirmation{JKT: "thumbprint"})},
+		{name: "certificate bound subject token", subjectToken: issue(&entity.Confirmation{X5tS256: "thumbprint"})},
+		{name: "bound actor token", subjectToken: unbound, actorToken: issue(&entity.Confirmation{JKT: "thumbprint"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := model.TokenRequest{
+				GrantTypes:       entity.TokenExchange,
+				SubjectToken:     tt.subjectToken,
+				SubjectTokenType: entity.TokenTypeAccessToken,
+				ActorToken:       tt.actorToken,
+			}
+			if tt.actorToken != "" {
+				payload.ActorTokenType = entity.TokenTypeAccessToken
+			}
+
+			resp := u.tokenExchange(context.Background(), entity.Channel{ClientId: "exchanger"}, payload)
+			if resp.Status() != response.StatInvalidGrant || resp.Message() != errorBoundTokenExchangeMessage {
+				t.Errorf("status %s message %q, want the bound token refused", resp.Status(), resp.Message())
+			}
+		})
+	}
+}
@@ -3,80 +3,300 @@ package oauth
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/umerthow/go-oauth/channel"
+	"github.com/umerthow/go-oauth/consent"
+	"github.com/umerthow/go-oauth/device"
 	"github.com/umerthow/go-oauth/entity"
 	tokenErr "github.com/umerthow/go-oauth/errors"
 	"github.com/umerthow/go-oauth/exception"
 	"github.com/umerthow/go-oauth/model"
 	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
 )
 
 const (
 	requestTokenSuccessMessage       = "Request Token Successfully"
 	verifyTokenSuccessMessage        = "Verify Token Successfully"
+	userInfoSuccessMessage           = "Get User Info Successfully"
+	errorInsufficientScopeMessage    = "Access Token Has No openid Scope"
+	errorNoUserLoginMessage          = "Access Token Was Not Issued For A User Login"
 	errorRequestTokenMessage         = "Request Token Failed!"
 	errorNotAllowRequestTokenMessage = "Request Not Allow To Grant Access Token"
+	errorCertificateRequiredMessage  = "Client Certificate Is Required"
+	errorDPoPRequiredMessage         = "DPoP Proof Is Required"
+	errorQuotaExceededMessage        = "Token Quota Of The Channel Is Exhausted"
+	errorBoundTokenExchangeMessage   = "Sender-Constrained Tokens Can't Be Exchanged"
+
+	// DefaultAccessTokenExpiresIn lifetime of an access token when none is configured
+	DefaultAccessTokenExpiresIn = time.Second * 300
 )
 
 type Usecase interface {
 	RequestToken(ctx context.Context, payload model.TokenRequest) response.Response
 	VerifyToken(ctx context.Context, payload model.TokenVerify) response.Response
+	UserInfo(ctx context.Context) response.Response
+	FindAuthorizeChannel(ctx context.Context, clientId, redirectURI string) (channel entity.Channel, err error)
+	Authorize(ctx context.Context, channel entity.Channel, payload model.AuthorizeRequest, session entity.Session) (code string, err error)
+	GrantConsent(ctx context.Context, channel entity.Channel, payload model.AuthorizeRequest, session entity.Session) (err error)
+	PushAuthorization(ctx context.Context, payload model.PushedAuthorizationRequest) response.Response
+	ResolveAuthorizeRequest(ctx context.Context, payload model.AuthorizeRequest) (model.AuthorizeRequest, error)
+	ValidateEndSession(ctx context.Context, payload model.EndSessionRequest) (redirectURI, subject string, err error)
+	EndSession(ctx context.Context, session entity.Session) (err error)
+}
+
+// AuditRecorder write security events to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+}
+
+// UserInfoFinder look up the standard claims of an end-user by subject
+type UserInfoFinder interface {
+	FindUserInfo(ctx context.Context, subject string) (userInfo entity.UserInfo, err error)
+}
+
+// UserStatusChecker tell whether an end-user may still be issued tokens
+type UserStatusChecker interface {
+	IsUserActive(ctx context.Context, subject string) (active bool, err error)
+}
+
+// UsageMeter count the tokens issued to a channel against its quota
+type UsageMeter interface {
+	Consume(ctx context.Context, clientId string, quota entity.Quota, at time.Time) (err error)
+	Release(ctx context.Context, clientId string, at time.Time) (err error)
 }
 
 type usecase struct {
-	serviceName       string
-	logger            *logrus.Logger
-	channelRepository channel.ChannelsRepository
-	loc               *time.Location
-	jwt               JWTAccessGenerate
+	serviceName                   string
+	logger                        *logrus.Logger
+	clientAuthenticator           channel.ClientAuthenticator
+	channelRepository             channel.ChannelsRepository
+	authorizationRepository       AuthorizationsRepository
+	pushedAuthorizationRepository PushedAuthorizationsRepository
+	refreshTokenRepository        RefreshTokensRepository
+	deviceRepository              device.DeviceRepository
+	consentRepository             consent.ConsentsRepository
+	userInfoFinder                UserInfoFinder
+	userStatusChecker             UserStatusChecker
+	usageMeter                    UsageMeter
+	auditRecorder                 AuditRecorder
+	loc                           *time.Location
+	jwt                           JWTAccessGenerate
+	accessTokenExpiresIn          time.Duration
+	refreshTokenExpiresIn         time.Duration
+	// logoutSlots bound the back-channel logout tokens being posted
+	logoutSlots chan struct{}
 }
 
 func NewOauthUsecase(property UsecaseOauthProperty) *usecase {
+	accessTokenExpiresIn := property.AccessTokenExpiresIn
+	if accessTokenExpiresIn <= 0 {
+		accessTokenExpiresIn = DefaultAccessTokenExpiresIn
+	}
+	refreshTokenExpiresIn := property.RefreshTokenExpiresIn
+	if refreshTokenExpiresIn <= 0 {
+		refreshTokenExpiresIn = DefaultRefreshTokenExpiresIn
+	}
+
 	return &usecase{
-		serviceName:       property.ServiceName,
-		logger:            property.Logger,
-		channelRepository: property.ChannelsRepository,
-		loc:               property.Location,
-		jwt:               property.JWT,
+		serviceName:                   property.ServiceName,
+		logger:                        property.Logger,
+		clientAuthenticator:           property.ClientAuthenticator,
+		channelRepository:             property.ChannelsRepository,
+		authorizationRepository:       property.AuthorizationRepository,
+		pushedAuthorizationRepository: property.PushedAuthorizationRepository,
+		refreshTokenRepository:        property.RefreshTokenRepository,
+		deviceRepository:              property.DeviceRepository,
+		consentRepository:             property.ConsentRepository,
+		userInfoFinder:                property.UserInfoFinder,
+		userStatusChecker:             property.UserStatusChecker,
+		usageMeter:                    property.UsageMeter,
+		auditRecorder:                 property.AuditRecorder,
+		loc:                           property.Location,
+		jwt:                           property.JWT,
+		accessTokenExpiresIn:          accessTokenExpiresIn,
+		refreshTokenExpiresIn:         refreshTokenExpiresIn,
+		logoutSlots:                   make(chan struct{}, BackchannelLogoutConcurrency),
 	}
 }
 
+// RequestToken issue a token for the grant, every outcome is audited
 func (u *usecase) RequestToken(ctx context.Context, payload model.TokenRequest) response.Response {
-	now := time.Now().In(u.loc)
+	ctx, span := tracing.Start(ctx, "oauth.RequestToken")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, payload.ClientId)
 
-	channel, err := u.channelRepository.FindOne(ctx, payload)
+	resp := u.requestToken(ctx, payload)
+
+	event := entity.AuditEvent{
+		Type:     entity.AuditTokenIssued,
+		Actor:    payload.ClientId,
+		ClientId: payload.ClientId,
+		Outcome:  entity.AuditSuccess,
+		Details:  map[string]string{"grant_type": string(payload.GrantTypes)},
+	}
+	if token, ok := resp.Data().(model.TokenClaimResponse); ok {
+		event.Details["scope"] = token.Scope
+	}
+	if resp.Error() != nil {
+		event.Type = entity.AuditTokenRefused
+		event.Outcome = entity.AuditFailure
+		event.Reason = resp.Message()
+		event.Details["status"] = resp.Status()
+	}
+	u.record(ctx, event)
+
+	return resp
+}
+
+func (u *usecase) requestToken(ctx context.Context, payload model.TokenRequest) response.Response {
+	channel, err := u.clientAuthenticator.Authenticate(ctx, payload.ClientAuthentication)
 	if err != nil {
 		if err == exception.ErrNotFound {
 			return response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, err.Error())
 		}
+		if err == tokenErr.ErrInvalidClient {
+			// only rejected credentials count towards the rate limit lockout,
+			// not the other refusals answered with the same status
+			entity.SetInvalidClientInContext(ctx)
+			return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorRequestTokenMessage)
+		}
 		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
 	}
-
-	if channel.SecretKey != payload.ClientSecret {
-		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorRequestTokenMessage)
-	}
+	entity.SetAuthenticatedClientIdInContext(ctx, channel.ClientId)
 
 	if len(channel.GrantTypes) <= 0 {
 		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorNotAllowRequestTokenMessage)
 	}
 
-	if channel.GrantTypes[0] != entity.ClientCredentials {
+	if !channel.HasGrantType(payload.GrantTypes) {
 		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorNotAllowRequestTokenMessage)
 	}
 
-	if payload.GrantTypes != entity.ClientCredentials {
+	if channel.TLSClientCertificateBoundTokens && entity.GetCertificateFromContext(ctx) == nil {
+		return response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatBadRequest, errorCertificateRequiredMessage)
+	}
+
+	if channel.DPoPBoundAccessTokens && GetDPoPThumbprintFromContext(ctx) == "" {
+		return response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatBadRequest, errorDPoPRequiredMessage)
+	}
+
+	switch payload.GrantTypes {
+	case entity.ClientCredentials:
+		return u.clientCredentials(ctx, channel, payload)
+	case entity.AuthorizationCode:
+		return u.authorizationCode(ctx, channel, payload)
+	case entity.DeviceCode:
+		return u.deviceCode(ctx, channel, payload)
+	case entity.TokenExchange:
+		return u.tokenExchange(ctx, channel, payload)
+	case entity.JWTBearer:
+		return u.jwtBearer(ctx, channel, payload)
+	case entity.Refreshing:
+		return u.refreshToken(ctx, channel, payload)
+	default:
 		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, errorNotAllowRequestTokenMessage)
 	}
+}
+
+func (u *usecase) clientCredentials(ctx context.Context, channel entity.Channel, payload model.TokenRequest) response.Response {
+	scopes := channel.Scopes
+	if payload.Scope != "" {
+		scopes = strings.Fields(payload.Scope)
+	}
+
+	if !channel.HasScopes(scopes) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, tokenErr.ErrInvalidScope.Error())
+	}
+
+	data := u.generateBasic(ctx, channel, scopes)
+
+	return u.issueToken(ctx, data)
+}
+
+func (u *usecase) deviceCode(ctx context.Context, channel entity.Channel, payload model.TokenRequest) response.Response {
+	now := time.Now().In(u.loc)
+
+	authorization, err := u.deviceRepository.FindByDeviceCode(ctx, payload.DeviceCode)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidGrant.Error())
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	if authorization.ClientId != channel.ClientId {
+		return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidGrant.Error())
+	}
+
+	if authorization.IsExpired(now) {
+		return response.NewErrorResponse(tokenErr.ErrExpiredToken, http.StatusBadRequest, nil, response.StatTokenExpired, tokenErr.ErrExpiredToken.Error())
+	}
+
+	// the device must respect the polling interval, every violation slows it down further (RFC 8628 section 3.5)
+	interval := authorization.Interval
+	isSlowDown := !authorization.LastPolledAt.IsZero() && now.Sub(authorization.LastPolledAt) < time.Duration(authorization.Interval)*time.Second
+	if isSlowDown {
+		interval += device.PollingInterval
+	}
+
+	if err := u.deviceRepository.UpdatePolling(ctx, authorization.ID, interval, now); err != nil {
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	if isSlowDown {
+		return response.NewErrorResponse(tokenErr.ErrSlowDown, http.StatusBadRequest, nil, response.StatSlowDown, tokenErr.ErrSlowDown.Error())
+	}
+
+	switch authorization.Status {
+	case entity.DeviceAuthorizationPending:
+		return response.NewErrorResponse(tokenErr.ErrAuthorizationPending, http.StatusBadRequest, nil, response.StatAuthorizationPending, tokenErr.ErrAuthorizationPending.Error())
+	case entity.DeviceAuthorizationDenied:
+		return response.NewErrorResponse(tokenErr.ErrAccessDenied, http.StatusBadRequest, nil, response.StatAccessDenied, tokenErr.ErrAccessDenied.Error())
+	}
+
+	// a device code can only be redeemed once, a concurrent poll that deleted it first wins
+	if err := u.deviceRepository.DeleteOne(ctx, authorization.ID); err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(tokenErr.ErrInvalidGrant, http.StatusBadRequest, nil, response.StatInvalidGrant, tokenErr.ErrInvalidGrant.Error())
+		}
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	data := u.generateBasic(ctx, channel, authorization.Scopes)
+	data.XDeviceId = authorization.XDeviceId
+	data.ID = authorization.UserID
+	data.AuthTime = authorization.AuthTime
+	data.AMR = authorization.AMR
+	data.Acr = authorization.Acr
+
+	return u.issueToken(ctx, data)
+}
+
+func (u *usecase) generateBasic(ctx context.Context, channel entity.Channel, scopes []string) *entity.GenerateBasic {
+	now := time.Now().In(u.loc)
 
 	deviceID := entity.GetDeviceIdFromContext(ctx)
 	isPublic := channel.ClientType == "public"
 
-	tokenExpiryIn := time.Second * 300 // dinamyc expires by client request
+	tokenExpiryIn := u.accessTokenExpiresIn
+
+	var cnf *entity.Confirmation
+	if cert := entity.GetCertificateFromContext(ctx); cert != nil && channel.TLSClientCertificateBoundTokens {
+		cnf = &entity.Confirmation{X5tS256: entity.CertificateThumbprint(cert)}
+	}
+	if jkt := GetDPoPThumbprintFromContext(ctx); jkt != "" {
+		if cnf == nil {
+			cnf = &entity.Confirmation{}
+		}
+		cnf.JKT = jkt
+	}
 
-	data := &entity.GenerateBasic{
+	return &entity.GenerateBasic{
 		ID:         channel.ID,
 		XDeviceId:  deviceID,
 		ClientId:   channel.ClientId,
@@ -84,27 +304,96 @@ func (u *usecase) RequestToken(ctx context.Context, payload model.TokenRequest)
 		IsPublic:   isPublic,
 		IsActive:   channel.IsActive,
 		GrantTypes: channel.GrantTypes,
-		Scopes:     channel.Scopes,
+		Scopes:     scopes,
 		CreateAt:   channel.CreatedAt,
 		Domain:     channel.RedirectURI,
+		Cnf:        cnf,
+		Quota:      channel.Quota,
 		TokenInfo: entity.TokenInfo{
+			ClientId:        channel.ClientId,
+			ClientSecret:    channel.SecretKey,
 			AccessCreateAt:  now,
 			AccessExpiresIn: tokenExpiryIn,
 			AccessExpiresAt: now.Add(tokenExpiryIn),
 		},
 	}
+}
 
-	access, _, err := u.jwt.Token(context.Background(), data, false)
-	token := model.TokenClaimResponse{
-		TokenType: "Bearer",
-		ExpiredAt: data.TokenInfo.GetAccessExpiresAt(),
-		Token:     access,
+func (u *usecase) issueToken(ctx context.Context, data *entity.GenerateBasic) response.Response {
+	token, err := u.token(ctx, data)
+	if err == tokenErr.ErrQuotaExceeded {
+		return response.NewErrorResponse(err, http.StatusTooManyRequests, nil, response.StatQuotaExceeded, errorQuotaExceededMessage)
+	}
+	// a channel that lost its secret since the grant started can't get an ID token anymore
+	if err == errMissingClientSecret {
+		return response.NewErrorResponse(tokenErr.ErrInvalidScope, http.StatusBadRequest, nil, response.StatInvalidScope, err.Error())
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error(err)
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorRequestTokenMessage)
 	}
 
 	return response.NewSuccessResponse(token, response.StatOK, requestTokenSuccessMessage)
 }
 
+func (u *usecase) token(ctx context.Context, data *entity.GenerateBasic) (token model.TokenClaimResponse, err error) {
+	if u.usageMeter != nil {
+		issuedAt := data.TokenInfo.GetAccessCreateAt()
+		if err = u.usageMeter.Consume(ctx, data.ClientId, data.Quota, issuedAt); err != nil {
+			return
+		}
+		// a token that fails to be issued isn't billed
+		defer func() {
+			if err == nil {
+				return
+			}
+			if errRelease := u.usageMeter.Release(ctx, data.ClientId, issuedAt); errRelease != nil {
+				u.logger.WithContext(ctx).Error(errRelease)
+			}
+		}()
+	}
+
+	access, refresh, err := u.jwt.Token(ctx, data, isRefreshable(data))
+	if err != nil {
+		return
+	}
+
+	tokenType := "Bearer"
+	if data.Cnf != nil && data.Cnf.JKT != "" {
+		tokenType = "DPoP"
+	}
+
+	token = model.TokenClaimResponse{
+		TokenType:       tokenType,
+		ExpiredAt:       data.TokenInfo.GetAccessExpiresAt(),
+		Token:           access,
+		Scope:           strings.Join(data.Scopes, " "),
+		IssuedTokenType: data.IssuedTokenType,
+	}
+
+	if refresh != "" {
+		if err = u.storeRefreshToken(ctx, data, refresh); err != nil {
+			return
+		}
+		token.RefreshToken = refresh
+	}
+
+	// an id_token describes an end-user authentication, never a client acting on its own
+	if hasScope(data.Scopes, entity.ScopeOpenID) && !data.AuthTime.IsZero() {
+		if token.IDToken, err = u.jwt.IDToken(ctx, data, access); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
 func (u *usecase) VerifyToken(ctx context.Context, payload model.TokenVerify) response.Response {
+	ctx, span := tracing.Start(ctx, "oauth.VerifyToken")
+	defer span.End()
+
+	entity.SetClientIdInContext(ctx, payload.ClientId)
+
 	claims, err := u.jwt.Verify(ctx, payload.Token)
 	if err != nil {
 		if err == tokenErr.ErrExpiredAccessToken {
@@ -113,10 +402,58 @@ func (u *usecase) VerifyToken(ctx context.Context, payload model.TokenVerify) re
 		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, err.Error())
 	}
 
+	// the token was issued by us to a registered channel
+	entity.SetAuthenticatedClientIdInContext(ctx, claims.ClientId)
+
+	// the caller is the resource server the token was presented to, not its
+	// holder. It checks the DPoP proof or the certificate it received against
+	// the cnf of the response (RFC 9449 section 6.2, RFC 8705 section 3.2).
 	responseData := model.TokenVerifyResponse{
 		ClientId: claims.ClientId,
 		Scopes:   claims.Scopes,
+		Cnf:      claims.Cnf,
+		AMR:      claims.AMR,
 	}
 
 	return response.NewSuccessResponse(responseData, response.StatOK, verifyTokenSuccessMessage)
 }
+
+func (u *usecase) UserInfo(ctx context.Context) response.Response {
+	ctx, span := tracing.Start(ctx, "oauth.UserInfo")
+	defer span.End()
+
+	claims := GetClaimsFromContext(ctx)
+	if claims == nil {
+		return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, tokenErr.ErrInvalidAccessToken.Error())
+	}
+
+	if !hasScope(claims.Scopes, entity.ScopeOpenID) {
+		return response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, errorInsufficientScopeMessage)
+	}
+
+	// the subject of a jwt-bearer or client credentials token is whatever the
+	// channel asserted, only the user who logged in can be described
+	if !claims.IsUserLogin() {
+		return response.NewErrorResponse(exception.ErrForbidden, http.StatusForbidden, nil, response.StatForbidden, errorNoUserLoginMessage)
+	}
+
+	userInfo := entity.UserInfo{Subject: claims.Subject}
+	if u.userInfoFinder != nil {
+		found, err := u.userInfoFinder.FindUserInfo(ctx, claims.Subject)
+		if err != nil {
+			if err == exception.ErrNotFound {
+				return response.NewErrorResponse(exception.ErrUnauthorized, http.StatusUnauthorized, nil, response.StatUnauthorized, tokenErr.ErrInvalidAccessToken.Error())
+			}
+			return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+		}
+		userInfo = found
+	}
+
+	return response.NewSuccessResponse(userInfo.Claims(claims.Scopes), response.StatOK, userInfoSuccessMessage)
+}
+
+func (u *usecase) record(ctx context.Context, event entity.AuditEvent) {
+	if u.auditRecorder != nil {
+		u.auditRecorder.Record(ctx, event)
+	}
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/response"
+)
+
+func TestUserInfoOnlyForUserLogins(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	jwtAccess := NewJWTAccessGenerate(KeyID(key), key, jwt.SigningMethodHS512)
+	authenticator := NewTokenAuthenticator(*jwtAccess, nil)
+	u := &usecase{jwt: *jwtAccess}
+
+	tests := []struct {
+		name     string
+		authTime time.Time
+		want     string
+	}{
+		{name: "authorization code of a login", authTime: time.Now().Add(-time.Minute), want: response.StatOK},
+		// jwt-bearer, the channel asserts the subject itself
+		{name: "asserted subject", want: response.StatForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access, _, err := jwtAccess.Token(context.Background(), &entity.GenerateBasic{
+				ID:        "user-1",
+				ClientId:  "client",
+				Scopes:    []string{entity.ScopeOpenID, entity.ScopeConsents},
+				AuthTime:  tt.authTime,
+				TokenInfo: entity.TokenInfo{AccessCreateAt: time.Now(), AccessExpiresIn: time.Minute},
+			}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/go-oauth/v1/userinfo", nil)
+			ctx, err := authenticator.AuthenticateToken(r, access)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp := u.UserInfo(ctx); resp.Status() != tt.want {
+				t.Errorf("userinfo status %s, want %s", resp.Status(), tt.want)
+			}
+			// nor can the consents of the subject be managed
+			if subject := entity.GetSubjectFromContext(ctx); (subject != "") != !tt.authTime.IsZero() {
+				t.Errorf("subject %q in the context", subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// pruneInterval how often full buckets are dropped so idle callers don't accumulate
+const pruneInterval = time.Minute
+
+// Buckets in-memory token buckets keyed by caller. Every replica keeps its
+// own buckets, the limit applies per instance.
+type Buckets struct {
+	mu       sync.Mutex
+	capacity float64
+	// rate tokens added per second
+	rate     float64
+	buckets  map[string]*bucket
+	prunedAt time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewBuckets buckets holding perMinute tokens, refilled at perMinute a minute
+func NewBuckets(perMinute int) *Buckets {
+	return &Buckets{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / time.Minute.Seconds(),
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Take remove a token from the bucket of the key. It returns the tokens left,
+// the time until the bucket is full and, when empty, the time until the next token.
+func (b *Buckets) Take(key string, now time.Time) (allowed bool, remaining int, reset, retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Sub(b.prunedAt) >= pruneInterval {
+		b.prune(now)
+	}
+
+	bk, ok := b.buckets[key]
+	if !ok {
+		bk = &bucket{tokens: b.capacity, updatedAt: now}
+		b.buckets[key] = bk
+	}
+	bk.tokens = b.refill(bk, now)
+	bk.updatedAt = now
+
+	if bk.tokens >= 1 {
+		bk.tokens--
+		allowed = true
+	} else {
+		retryAfter = b.duration(1 - bk.tokens)
+	}
+
+	return allowed, int(bk.tokens), b.duration(b.capacity - bk.tokens), retryAfter
+}
+
+// Limit capacity of every bucket
+func (b *Buckets) Limit() int {
+	return int(b.capacity)
+}
+
+func (b *Buckets) refill(bk *bucket, now time.Time) float64 {
+	return math.Min(b.capacity, bk.tokens+now.Sub(bk.updatedAt).Seconds()*b.rate)
+}
+
+func (b *Buckets) duration(tokens float64) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / b.rate * float64(time.Second)))
+}
+
+func (b *Buckets) prune(now time.Time) {
+	for key, bk := range b.buckets {
+		if b.refill(bk, now) >= b.capacity {
+			delete(b.buckets, key)
+		}
+	}
+	b.prunedAt = now
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+const (
+	// LockoutDuration first lockout, it doubles with every failure after the threshold
+	LockoutDuration = time.Minute
+	// MaxLockoutDuration longest lockout
+	MaxLockoutDuration = time.Hour
+	// FailureWindow failures are forgotten this long after the last one or the end of its lockout
+	FailureWindow = time.Minute * 15
+)
+
+// Limiter token buckets per client id and per address, and a progressive
+// lockout of a client id from an address after failed client authentications
+type Limiter struct {
+	clients          *Buckets
+	addresses        *Buckets
+	lockouts         LockoutStore
+	lockoutThreshold int
+}
+
+// NewLimiter is a constructor
+func NewLimiter(property LimiterProperty) *Limiter {
+	return &Limiter{
+		clients:          NewBuckets(property.ClientPerMinute),
+		addresses:        NewBuckets(property.AddressPerMinute),
+		lockouts:         property.LockoutStore,
+		lockoutThreshold: property.LockoutThreshold,
+	}
+}
+
+// Allow check the lockout then take a token from the client and address buckets
+func (l *Limiter) Allow(ctx context.Context, clientId, address string) (limit entity.RateLimit, err error) {
+	now := time.Now()
+
+	if clientId != "" {
+		lockout, err := l.lockouts.FindOne(ctx, lockoutKey(clientId, address))
+		if err != nil && err != exception.ErrNotFound {
+			return limit, err
+		}
+		if until := l.lockedUntil(lockout); err == nil && now.Before(until) {
+			return entity.RateLimit{Locked: true, Limit: l.clients.Limit(), Reset: until.Sub(now), RetryAfter: until.Sub(now)}, nil
+		}
+	}
+
+	limit = take(l.addresses, address, now)
+	if limit.Allowed && clientId != "" {
+		if client := take(l.clients, clientId, now); !client.Allowed || client.Remaining < limit.Remaining {
+			limit = client
+		}
+	}
+
+	return limit, nil
+}
+
+// RecordFailure count a failed client authentication, stale failures start over
+func (l *Limiter) RecordFailure(ctx context.Context, clientId, address string) (err error) {
+	now := time.Now()
+	key := lockoutKey(clientId, address)
+
+	lockout, err := l.lockouts.FindOne(ctx, key)
+	if err != nil && err != exception.ErrNotFound {
+		return
+	}
+	if err == nil && now.Sub(l.lockedUntil(lockout)) > FailureWindow {
+		if err = l.lockouts.DeleteOne(ctx, key); err != nil {
+			return
+		}
+	}
+
+	_, err = l.lockouts.RecordFailure(ctx, key, now)
+	return
+}
+
+// RecordSuccess forget the failures once the client authenticates
+func (l *Limiter) RecordSuccess(ctx context.Context, clientId, address string) (err error) {
+	return l.lockouts.DeleteOne(ctx, lockoutKey(clientId, address))
+}
+
+// lockedUntil end of the lockout, the last failure when below the threshold
+func (l *Limiter) lockedUntil(lockout entity.Lockout) time.Time {
+	if lockout.Failures < l.lockoutThreshold {
+		return lockout.UpdatedAt
+	}
+
+	duration := LockoutDuration
+	for i := l.lockoutThreshold; i < lockout.Failures && duration < MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > MaxLockoutDuration {
+		duration = MaxLockoutDuration
+	}
+
+	return lockout.UpdatedAt.Add(duration)
+}
+
+func take(buckets *Buckets, key string, now time.Time) entity.RateLimit {
+	allowed, remaining, reset, retryAfter := buckets.Take(key, now)
+
+	return entity.RateLimit{
+		Allowed:    allowed,
+		Limit:      buckets.Limit(),
+		Remaining:  remaining,
+		Reset:      reset,
+		RetryAfter: retryAfter,
+	}
+}
+
+// lockoutKey a client is locked out per address, a guesser can't lock the
+// genuine client out from everywhere
+func lockoutKey(clientId, address string) string {
+	return clientId + "|" + address
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+)
+
+func newTestLimiter(threshold int) *Limiter {
+	return NewLimiter(LimiterProperty{
+		ClientPerMinute:  1000,
+		AddressPerMinute: 1000,
+		LockoutThreshold: threshold,
+		LockoutStore:     NewMemoryLockoutStore(),
+	})
+}
+
+func TestLimiterLockout(t *testing.T) {
+	ctx := context.Background()
+	limiter := newTestLimiter(3)
+
+	for i := 0; i < 3; i++ {
+		limit, err := limiter.Allow(ctx, "client", "203.0.113.1")
+		if err != nil || limit.Locked {
+			t.Fatalf("attempt %d: locked %v, err %v", i+1, limit.Locked, err)
+		}
+		if err := limiter.RecordFailure(ctx, "client", "203.0.113.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	limit, err := limiter.Allow(ctx, "client", "203.0.113.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !limit.Locked {
+		t.Fatal("client not locked out after reaching the threshold")
+	}
+	if limit.RetryAfter <= 0 || limit.RetryAfter > LockoutDuration {
+		t.Errorf("retry after %v, want within the first lockout of %v", limit.RetryAfter, LockoutDuration)
+	}
+
+	// the lockout is per address, the genuine client elsewhere isn't locked out
+	if limit, _ := limiter.Allow(ctx, "client", "198.51.100.7"); limit.Locked {
+		t.Error("client locked out from another address")
+	}
+	// nor is another client from the same address
+	if limit, _ := limiter.Allow(ctx, "other", "203.0.113.1"); limit.Locked {
+		t.Error("another client locked out from the address")
+	}
+}
+
+func TestLimiterSuccessClearsFailures(t *testing.T) {
+	ctx := context.Background()
+	limiter := newTestLimiter(2)
+
+	if err := limiter.RecordFailure(ctx, "client", "203.0.113.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.RecordSuccess(ctx, "client", "203.0.113.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.RecordFailure(ctx, "client", "203.0.113.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if limit, _ := limiter.Allow(ctx, "client", "203.0.113.1"); limit.Locked {
+		t.Error("failures before a success still count")
+	}
+}
+
+func TestLockedUntilDoublesAndCaps(t *testing.T) {
+	limiter := newTestLimiter(3)
+	updatedAt := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{2, 0},
+		{3, LockoutDuration},
+		{4, 2 * LockoutDuration},
+		{5, 4 * LockoutDuration},
+		{100, MaxLockoutDuration},
+	}
+
+	for _, tt := range tests {
+		lockout := entity.Lockout{Failures: tt.failures, UpdatedAt: updatedAt}
+		if got := limiter.lockedUntil(lockout).Sub(updatedAt); got != tt.want {
+			t.Errorf("%d failures: locked for %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
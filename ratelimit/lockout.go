@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+// LockoutStore keep the failed client authentications, a shared store
+// enforces the lockout across every replica
+type LockoutStore interface {
+	// FindOne exception.ErrNotFound when the key has no failure
+	FindOne(ctx context.Context, key string) (lockout entity.Lockout, err error)
+	// RecordFailure count one more failure and return the updated record
+	RecordFailure(ctx context.Context, key string, now time.Time) (lockout entity.Lockout, err error)
+	DeleteOne(ctx context.Context, key string) (err error)
+	// EnsureIndexes create the index removing the failures once they are forgotten
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type memoryLockoutStore struct {
+	mu       sync.Mutex
+	lockouts map[string]entity.Lockout
+}
+
+// NewMemoryLockoutStore lockouts kept in the process, for a single instance and tests
+func NewMemoryLockoutStore() LockoutStore {
+	return &memoryLockoutStore{lockouts: make(map[string]entity.Lockout)}
+}
+
+func (s *memoryLockoutStore) FindOne(ctx context.Context, key string) (entity.Lockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockout, ok := s.lockouts[key]
+	if !ok {
+		return entity.Lockout{}, exception.ErrNotFound
+	}
+	return lockout, nil
+}
+
+func (s *memoryLockoutStore) RecordFailure(ctx context.Context, key string, now time.Time) (entity.Lockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockout := s.lockouts[key]
+	lockout.Key = key
+	lockout.Failures++
+	lockout.UpdatedAt = now
+	s.lockouts[key] = lockout
+
+	return lockout, nil
+}
+
+func (s *memoryLockoutStore) DeleteOne(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.lockouts, key)
+	return nil
+}
+
+func (s *memoryLockoutStore) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,11 @@
+package ratelimit
+
+type LimiterProperty struct {
+	// ClientPerMinute requests of a client id, shared by all its addresses
+	ClientPerMinute int
+	// AddressPerMinute requests of an address, whatever the client id
+	AddressPerMinute int
+	// LockoutThreshold failed client authentications before the lockout starts
+	LockoutThreshold int
+	LockoutStore     LockoutStore
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type lockoutRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+// NewLockoutRepository lockouts shared by every replica through mongodb
+func NewLockoutRepository(logger *logrus.Logger, db mongodb.Database) LockoutStore {
+	col := db.Collection("oauth_rate_limit_lockout")
+	return &lockoutRepository{logger, col}
+}
+
+func (r *lockoutRepository) FindOne(ctx context.Context, key string) (lockout entity.Lockout, err error) {
+	if err = r.col.FindOne(ctx, bson.M{"key": key}).Decode(&lockout); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *lockoutRepository) RecordFailure(ctx context.Context, key string, now time.Time) (lockout entity.Lockout, err error) {
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"updated_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	if err = r.col.FindOneAndUpdate(ctx, bson.M{"key": key}, update, opts).Decode(&lockout); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *lockoutRepository) DeleteOne(ctx context.Context, key string) (err error) {
+	if _, err = r.col.DeleteOne(ctx, bson.M{"key": key}); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+// EnsureIndexes a record is stale FailureWindow after the longest lockout
+// ends, whatever the number of failures it counts. The unique key keeps
+// concurrent upserts of RecordFailure on a single record.
+func (r *lockoutRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col,
+		mongodb.UniqueIndex("key"),
+		mongodb.TTLIndex("updated_at", MaxLockoutDuration+FailureWindow),
+	); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 )
 
+// RequestIDHeader header carrying the identifier of a request
+const RequestIDHeader = "X-Request-ID"
+
 // REST is a collection of behavior of REST.
 type REST interface {
 	JSON(w http.ResponseWriter)
@@ -17,6 +20,8 @@ type restObject struct {
 	Status  string      `json:"status"`
 	Code    int         `json:"code"`
 	Meta    interface{} `json:"meta,omitempty"` // will not be appeared if not set.
+	// echoed from the X-Request-ID response header, absent outside of a request
+	RequestID string `json:"requestId,omitempty"`
 	// can add more
 }
 
@@ -66,6 +71,8 @@ func JSON(w http.ResponseWriter, resp Response) {
 		Status:  resp.Status(),
 		Code:    resp.HTTPStatusCode(),
 		Meta:    resp.Meta(),
+		// set by the request context middleware
+		RequestID: w.Header().Get(RequestIDHeader),
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(ro.Code)
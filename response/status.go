@@ -2,17 +2,34 @@ package response
 
 // Collection of status.
 const (
-	StatOK                 string = "OK"
-	StatCreated            string = "CREATED"
-	StatNotFound           string = "NOT_FOUND"
-	StatUnexpectedError    string = "UNEXPECTED_ERROR"
-	StatInsufficientPoint  string = "INSUFFICIENT_POINT"
-	StatusInvalidPayload   string = "INVALID_PAYLOAD"
-	StatusInvalidParameter string = "INVALID_PARAMETER"
-	StatUnauthorized       string = "UNAUTHORIZED"
-	StatTokenExpired       string = "EXPIRED"
-	StatAlreadyExist       string = "ALREADY_EXIST"
-	StatBadRequest         string = "BAD_REQUEST"
-	StatForbidden          string = "FORBIDDEN"
-	StatRequestTimeout     string = "REQUEST_TIMEOUT"
+	StatOK                   string = "OK"
+	StatCreated              string = "CREATED"
+	StatNotFound             string = "NOT_FOUND"
+	StatUnexpectedError      string = "UNEXPECTED_ERROR"
+	StatInsufficientPoint    string = "INSUFFICIENT_POINT"
+	StatusInvalidPayload     string = "INVALID_PAYLOAD"
+	StatusInvalidParameter   string = "INVALID_PARAMETER"
+	StatUnauthorized         string = "UNAUTHORIZED"
+	StatTokenExpired         string = "EXPIRED"
+	StatAlreadyExist         string = "ALREADY_EXIST"
+	StatBadRequest           string = "BAD_REQUEST"
+	StatForbidden            string = "FORBIDDEN"
+	StatRequestTimeout       string = "REQUEST_TIMEOUT"
+	StatInvalidGrant         string = "INVALID_GRANT"
+	StatInvalidScope         string = "INVALID_SCOPE"
+	StatInvalidTarget        string = "INVALID_TARGET"
+	StatInvalidDPoPProof     string = "INVALID_DPOP_PROOF"
+	StatUseDPoPNonce         string = "USE_DPOP_NONCE"
+	StatAuthorizationPending string = "AUTHORIZATION_PENDING"
+	StatSlowDown             string = "SLOW_DOWN"
+	StatAccessDenied         string = "ACCESS_DENIED"
+	StatInvalidClientMeta    string = "INVALID_CLIENT_METADATA"
+	StatInvalidRedirectURI   string = "INVALID_REDIRECT_URI"
+	StatInvalidRequestURI    string = "INVALID_REQUEST_URI"
+	StatInvalidRequestObject string = "INVALID_REQUEST_OBJECT"
+	StatInvalidIDTokenHint   string = "INVALID_ID_TOKEN_HINT"
+	StatInvalidPostLogoutURI string = "INVALID_POST_LOGOUT_REDIRECT_URI"
+	StatTooManyRequests      string = "TOO_MANY_REQUESTS"
+	StatQuotaExceeded        string = "QUOTA_EXCEEDED"
+	StatServiceUnavailable   string = "SERVICE_UNAVAILABLE"
 )
@@ -2,51 +2,201 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	startingMessage string = "HTTP Server starts to listen on %s"
-	shutdownMessage string = "HTTP Server is gracefully shutdown."
+	startingMessage     string = "HTTP Server starts to listen on %s"
+	startingTLSMessage  string = "HTTPS Server starts to listen on %s"
+	shutdownMessage     string = "HTTP Server is gracefully shutdown."
+	shuttingDownMessage string = "HTTP Server is shutting down, draining in-flight requests."
+	drainTimeoutMessage string = "HTTP Server drain timed out, closing the remaining connections."
 )
 
 // Server is a concrete struct of http server.
 type Server struct {
 	logger     *logrus.Logger
 	httpServer *http.Server
+	certFile   string
+	keyFile    string
+	errs       chan error
+	inFlight   atomic.Int64
+	// ctx shared by the background workers, cancelled on shutdown
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+// Timeouts of the connections of the server.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+	Idle  time.Duration
 }
 
 // NewServer is a constructor.
-func NewServer(logger *logrus.Logger, handler http.Handler, port string) *Server {
+func NewServer(logger *logrus.Logger, handler http.Handler, port int, timeouts Timeouts) *Server {
 	httpServer := &http.Server{
-		Addr:         fmt.Sprintf(":%s", port),
-		WriteTimeout: time.Second * 60,
-		ReadTimeout:  time.Second * 30,
-		IdleTimeout:  time.Second * 60,
-		Handler:      handler,
+		Addr:         fmt.Sprintf(":%d", port),
+		WriteTimeout: timeouts.Write,
+		ReadTimeout:  timeouts.Read,
+		IdleTimeout:  timeouts.Idle,
 	}
 
-	return &Server{
+	s := &Server{
 		logger:     logger,
 		httpServer: httpServer,
+		errs:       make(chan error, 1),
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	httpServer.Handler = s.track(handler)
+
+	return s
+}
+
+// NewTLSServer is a constructor of a server listening with TLS.
+// When requestClientCert is true the client is asked for a certificate, its
+// verification is left to the client authentication method of the channel
+// so self-signed certificates can be accepted as well.
+func NewTLSServer(logger *logrus.Logger, handler http.Handler, port int, timeouts Timeouts, certFile, keyFile string, requestClientCert bool) *Server {
+	s := NewServer(logger, handler, port, timeouts)
+	s.certFile = certFile
+	s.keyFile = keyFile
+
+	clientAuth := tls.NoClientCert
+	if requestClientCert {
+		clientAuth = tls.RequestClientCert
+	}
+	s.httpServer.TLSConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+	}
+
+	return s
+}
+
+// LoadCertPool read the PEM encoded certificates of a file into a pool.
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no certificate found in " + file)
+	}
+
+	return pool, nil
+}
+
+// Start open the listener and serve in the background. A listener or a
+// certificate that can't be opened is returned, a failure while serving is
+// sent on Err.
+func (s *Server) Start() error {
+	if s.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		var err error
+		if s.certFile != "" {
+			s.logger.Info(fmt.Sprintf(startingTLSMessage, s.httpServer.Addr))
+			err = s.httpServer.ServeTLS(listener, "", "")
+		} else {
+			s.logger.Info(fmt.Sprintf(startingMessage, s.httpServer.Addr))
+			err = s.httpServer.Serve(listener)
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- err
+		}
+	}()
+
+	return nil
+}
+
+// Err receive the error the server stopped serving on
+func (s *Server) Err() <-chan error {
+	return s.errs
 }
 
-// Start will start the server.
-// Do not call this in goroutine.
-func (s *Server) Start() {
+// Go run a background worker until the server shuts down, its context is
+// cancelled once the in-flight requests are drained.
+func (s *Server) Go(worker func(ctx context.Context)) {
+	s.workers.Add(1)
 	go func() {
-		s.logger.Info(fmt.Sprintf(startingMessage, s.httpServer.Addr))
-		s.httpServer.ListenAndServe()
+		defer s.workers.Done()
+		worker(s.ctx)
 	}()
 }
 
-// Close will block all the incomming request and subsequently shutdown the server.
-func (s *Server) Close() {
-	s.httpServer.Shutdown(context.Background())
+// InFlight number of requests being served
+func (s *Server) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// Shutdown stop accepting connections, wait for the in-flight requests then
+// for the background workers. When ctx expires first the remaining
+// connections are closed and the drain error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.WithField("in_flight", s.InFlight()).Info(shuttingDownMessage)
+
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		s.logger.WithField("in_flight", s.InFlight()).Warn(drainTimeoutMessage)
+		s.httpServer.Close()
+	}
+
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("server shutdown: %w", err)
+	}
+
 	s.logger.Info(shutdownMessage)
+	return nil
+}
+
+// track count the requests being served
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/umerthow/go-oauth/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware start a server span for the request, continuing the trace of its traceparent header
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx, span := Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+
+		recorder := middleware.NewStatusRecorder(w)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.Status))
+		if recorder.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.Status))
+		}
+	})
+}
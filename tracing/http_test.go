@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus/hooks/test"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func newTestProvider(t *testing.T) *Provider {
+	provider, err := NewProvider("go-oauth-test", ExporterMemory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { provider.Memory.Reset() })
+	return provider
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddlewareContinuesTheTrace(t *testing.T) {
+	provider := newTestProvider(t)
+	logger, entries := test.NewNullLogger()
+	logger.AddHook(LogHook{})
+
+	router := mux.NewRouter()
+	router.Use(Middleware)
+	router.HandleFunc("/go-oauth/v1/clients/{clientId}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "channel.GetClient")
+		span.End()
+		logger.WithContext(r.Context()).Info("served")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/go-oauth/v1/clients/client-1", nil)
+	req.Header.Set("traceparent", traceparent)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := provider.Memory.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans exported, want 2", len(spans))
+	}
+	child, server := spans[0], spans[1]
+
+	// the span is named after the route, not the path carrying the client id
+	if server.Name != "GET /go-oauth/v1/clients/{clientId}" {
+		t.Errorf("server span %q", server.Name)
+	}
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span kind %v", server.SpanKind)
+	}
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id %s, want the one of the traceparent", got)
+	}
+	if got := server.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id %s, want the one of the traceparent", got)
+	}
+	if got := attributeValue(server, "http.response.status_code").AsInt64(); got != http.StatusInternalServerError {
+		t.Errorf("status code attribute %d", got)
+	}
+	if server.Status.Code != codes.Error {
+		t.Errorf("status %v of a failed request, want error", server.Status.Code)
+	}
+
+	if child.Name != "channel.GetClient" || child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("span %q isn't a child of the server span", child.Name)
+	}
+
+	entry := entries.LastEntry()
+	if entry == nil || entry.Data["trace_id"] != server.SpanContext.TraceID().String() || entry.Data["span_id"] != server.SpanContext.SpanID().String() {
+		t.Errorf("log entry not correlated with the server span: %v", entry)
+	}
+}
+
+func TestLogHookOutsideOfASpan(t *testing.T) {
+	newTestProvider(t)
+	logger, entries := test.NewNullLogger()
+	logger.AddHook(LogHook{})
+
+	logger.WithContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()).Info("no span")
+	logger.Info("no context")
+
+	for _, entry := range entries.AllEntries() {
+		if _, ok := entry.Data["trace_id"]; ok {
+			t.Errorf("entry %q has a trace id", entry.Message)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook add the trace and span ids of the entry context to entries logged WithContext
+type LogHook struct{}
+
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	entry.Data["trace_id"] = spanContext.TraceID().String()
+	entry.Data["span_id"] = spanContext.SpanID().String()
+	return nil
+}
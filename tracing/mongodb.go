@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedDatabase struct {
+	mongodb.Database
+}
+
+// InstrumentDatabase start a client span for every operation of the collections of the database
+func InstrumentDatabase(db mongodb.Database) mongodb.Database {
+	return &tracedDatabase{db}
+}
+
+func (db *tracedDatabase) Collection(name string, opts ...*options.CollectionOptions) mongodb.Collection {
+	return &tracedCollection{db.Database.Collection(name, opts...), name}
+}
+
+type tracedCollection struct {
+	col  mongodb.Collection
+	name string
+}
+
+func (c *tracedCollection) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return Start(ctx, "mongodb."+c.name+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.collection.name", c.name),
+			attribute.String("db.operation.name", operation),
+		),
+	)
+}
+
+// end the span, a missing document is an answer rather than a failed operation
+func end(span trace.Span, err error) {
+	if err != nil && err != mongo.ErrNoDocuments {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (c *tracedCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) mongodb.SingleResult {
+	ctx, span := c.start(ctx, "findOne")
+	result := c.col.FindOne(ctx, filter, opts...)
+	end(span, result.Err())
+	return result
+}
+
+func (c *tracedCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongodb.SingleResult {
+	ctx, span := c.start(ctx, "findOneAndUpdate")
+	result := c.col.FindOneAndUpdate(ctx, filter, update, opts...)
+	end(span, result.Err())
+	return result
+}
+
+func (c *tracedCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongodb.Cursor, error) {
+	ctx, span := c.start(ctx, "find")
+	cursor, err := c.col.Find(ctx, filter, opts...)
+	end(span, err)
+	return cursor, err
+}
+
+func (c *tracedCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	ctx, span := c.start(ctx, "insertOne")
+	result, err := c.col.InsertOne(ctx, document, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	ctx, span := c.start(ctx, "insertMany")
+	result, err := c.col.InsertMany(ctx, documents, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	ctx, span := c.start(ctx, "countDocuments")
+	counted, err := c.col.CountDocuments(ctx, filter, opts...)
+	end(span, err)
+	return counted, err
+}
+
+func (c *tracedCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, span := c.start(ctx, "deleteOne")
+	result, err := c.col.DeleteOne(ctx, filter, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, span := c.start(ctx, "deleteMany")
+	result, err := c.col.DeleteMany(ctx, filter, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, span := c.start(ctx, "updateMany")
+	result, err := c.col.UpdateMany(ctx, filter, update, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, span := c.start(ctx, "updateOne")
+	result, err := c.col.UpdateOne(ctx, filter, update, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	ctx, span := c.start(ctx, "bulkWrite")
+	result, err := c.col.BulkWrite(ctx, models, opts...)
+	end(span, err)
+	return result, err
+}
+
+func (c *tracedCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (mongodb.Cursor, error) {
+	ctx, span := c.start(ctx, "aggregate")
+	cursor, err := c.col.Aggregate(ctx, pipeline, opts...)
+	end(span, err)
+	return cursor, err
+}
+
+func (c *tracedCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	ctx, span := c.start(ctx, "createIndexes")
+	names, err := c.col.CreateIndexes(ctx, models, opts...)
+	end(span, err)
+	return names, err
+}
+
+func (c *tracedCollection) DropIndex(ctx context.Context, name string, opts ...*options.DropIndexesOptions) error {
+	ctx, span := c.start(ctx, "dropIndexes")
+	err := c.col.DropIndex(ctx, name, opts...)
+	end(span, err)
+	return err
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterMemory = "memory"
+)
+
+const instrumentationName = "github.com/umerthow/go-oauth"
+
+// Provider tracer provider of the service
+type Provider struct {
+	*sdktrace.TracerProvider
+	// Memory spans kept by the memory exporter, nil with any other exporter
+	Memory *tracetest.InMemoryExporter
+}
+
+// NewProvider set the global tracer provider and the W3C trace context propagator.
+// With no exporter spans aren't sampled, trace ids are still propagated and logged.
+func NewProvider(serviceName, exporter string) (*Provider, error) {
+	provider := new(Provider)
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	}
+
+	switch exporter {
+	case ExporterNone:
+		opts = append(opts, sdktrace.WithSampler(sdktrace.NeverSample()))
+	case ExporterStdout:
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(stdout))
+	case ExporterMemory:
+		provider.Memory = tracetest.NewInMemoryExporter()
+		opts = append(opts, sdktrace.WithSyncer(provider.Memory))
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", exporter)
+	}
+
+	provider.TracerProvider = sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider.TracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider, nil
+}
+
+// Start a span named after the operation, a child of the span of the context
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// Inject the trace context in the headers of an outgoing request
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
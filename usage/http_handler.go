@@ -0,0 +1,63 @@
+package usage
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/middleware"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+type HTTPHandler struct {
+	Logger   *logrus.Logger
+	Validate *validator.Validate
+	Usecase  Usecase
+}
+
+func NewUsageHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, basicAuth middleware.RouteMiddleware, usecase Usecase) {
+	handler := &HTTPHandler{
+		Logger:   logger,
+		Validate: validate,
+		Usecase:  usecase,
+	}
+
+	router.HandleFunc("/go-oauth/v1/usage", basicAuth.Verify(handler.GetUsage)).Methods(http.MethodGet)
+}
+
+func (handler *HTTPHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	payload := model.UsageQuery{
+		ClientId:    query.Get("clientId"),
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		Granularity: query.Get("granularity"),
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp := response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidParameter, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp := handler.Usecase.GetUsage(ctx, payload)
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) validateRequestBody(body interface{}) (err error) {
+	err = handler.Validate.Struct(body)
+	if err == nil {
+		return
+	}
+
+	errorFields := err.(validator.ValidationErrors)
+	errorField := errorFields[0]
+	err = fmt.Errorf("invalid '%s' with value '%v'", errorField.Field(), errorField.Value())
+
+	return
+}
@@ -0,0 +1,14 @@
+package usage
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type UsecaseUsageProperty struct {
+	ServiceName     string
+	Logger          *logrus.Logger
+	Location        *time.Location
+	UsageRepository UsageRepository
+}
@@ -0,0 +1,120 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type UsageRepository interface {
+	// Increment add delta to the counter of the period and return its new value
+	Increment(ctx context.Context, clientId, period string, start time.Time, delta int64, now time.Time) (count int64, err error)
+	// Aggregate sum the daily counters from up to to, grouped by channel and by
+	// the $dateToString format of their day in the time zone
+	Aggregate(ctx context.Context, clientId string, from, to time.Time, format string, loc *time.Location) (usages []model.UsageResponse, err error)
+	// EnsureIndexes keep a single counter per channel and period and serve the
+	// aggregation of a day range
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type usageRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewUsageRepository(logger *logrus.Logger, db mongodb.Database) UsageRepository {
+	col := db.Collection("oauth_usage")
+	return &usageRepository{logger, col}
+}
+
+func (r *usageRepository) Increment(ctx context.Context, clientId, period string, start time.Time, delta int64, now time.Time) (count int64, err error) {
+	filter := bson.M{
+		"client_id": clientId,
+		"period":    period,
+		"start":     start,
+	}
+	update := bson.M{
+		"$inc": bson.M{"count": delta},
+		"$set": bson.M{"updated_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var usage entity.Usage
+	if err = r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&usage); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	return usage.Count, nil
+}
+
+func (r *usageRepository) Aggregate(ctx context.Context, clientId string, from, to time.Time, format string, loc *time.Location) (usages []model.UsageResponse, err error) {
+	match := bson.M{
+		"period": entity.UsagePeriodDay,
+		"start":  bson.M{"$gte": from, "$lt": to},
+	}
+	if clientId != "" {
+		match["client_id"] = clientId
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"client_id": "$client_id",
+				"period":    bson.M{"$dateToString": bson.M{"format": format, "date": "$start", "timezone": loc.String()}},
+			},
+			"count": bson.M{"$sum": "$count"},
+		}},
+		{"$project": bson.M{
+			"_id":       0,
+			"client_id": "$_id.client_id",
+			"period":    "$_id.period",
+			"count":     1,
+		}},
+		{"$sort": bson.D{{Key: "client_id", Value: 1}, {Key: "period", Value: 1}}},
+	}
+
+	cursor, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+	defer cursor.Close(ctx)
+
+	usages = []model.UsageResponse{}
+	for cursor.Next(ctx) {
+		var usage model.UsageResponse
+		if err = cursor.Decode(&usage); err != nil {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		usages = append(usages, usage)
+	}
+
+	return
+}
+
+// EnsureIndexes the unique counter key keeps concurrent upserts of Increment
+// on a single document and serves Aggregate for one channel, the period and
+// start index serves it for all of them.
+func (r *usageRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col,
+		mongodb.UniqueIndex("client_id", "period", "start"),
+		mongodb.Index("period", "start"),
+	); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
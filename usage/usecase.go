@@ -0,0 +1,157 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+)
+
+const (
+	getUsageSuccessMessage = "Get Usage Successfully"
+	errorUsageRangeMessage = "The Usage Range Must End After It Starts"
+
+	dayLayout = "2006-01-02"
+)
+
+type Usecase interface {
+	Consume(ctx context.Context, clientId string, quota entity.Quota, at time.Time) (err error)
+	Release(ctx context.Context, clientId string, at time.Time) (err error)
+	GetUsage(ctx context.Context, query model.UsageQuery) response.Response
+}
+
+type usecase struct {
+	serviceName     string
+	logger          *logrus.Logger
+	usageRepository UsageRepository
+	loc             *time.Location
+}
+
+func NewUsageUsecase(property UsecaseUsageProperty) *usecase {
+	return &usecase{
+		serviceName:     property.ServiceName,
+		logger:          property.Logger,
+		usageRepository: property.UsageRepository,
+		loc:             property.Location,
+	}
+}
+
+// Consume count a token issued to the channel at the given time. Once a
+// quota is exhausted the count is given back and tokenErr.ErrQuotaExceeded
+// returned, crossing the alert share of a limit logs a warning.
+func (u *usecase) Consume(ctx context.Context, clientId string, quota entity.Quota, at time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, "usage.Consume")
+	defer span.End()
+
+	day, month := u.periods(at)
+
+	daily, err := u.usageRepository.Increment(ctx, clientId, entity.UsagePeriodDay, day, 1, at)
+	if err != nil {
+		return
+	}
+
+	monthly, err := u.usageRepository.Increment(ctx, clientId, entity.UsagePeriodMonth, month, 1, at)
+	if err != nil {
+		u.decrement(ctx, clientId, entity.UsagePeriodDay, day, at)
+		return
+	}
+
+	if isExceeded(daily, quota.Daily) || isExceeded(monthly, quota.Monthly) {
+		u.decrement(ctx, clientId, entity.UsagePeriodDay, day, at)
+		u.decrement(ctx, clientId, entity.UsagePeriodMonth, month, at)
+		return tokenErr.ErrQuotaExceeded
+	}
+
+	u.alert(ctx, clientId, entity.UsagePeriodDay, daily, quota.Daily, quota.AlertPercent)
+	u.alert(ctx, clientId, entity.UsagePeriodMonth, monthly, quota.Monthly, quota.AlertPercent)
+
+	return nil
+}
+
+// Release give back a token counted by Consume that was not issued after all
+func (u *usecase) Release(ctx context.Context, clientId string, at time.Time) (err error) {
+	ctx, span := tracing.Start(ctx, "usage.Release")
+	defer span.End()
+
+	day, month := u.periods(at)
+
+	if _, err = u.usageRepository.Increment(ctx, clientId, entity.UsagePeriodDay, day, -1, at); err != nil {
+		return
+	}
+	_, err = u.usageRepository.Increment(ctx, clientId, entity.UsagePeriodMonth, month, -1, at)
+
+	return
+}
+
+// GetUsage tokens issued per channel and per day or month of the range
+func (u *usecase) GetUsage(ctx context.Context, query model.UsageQuery) response.Response {
+	ctx, span := tracing.Start(ctx, "usage.GetUsage")
+	defer span.End()
+
+	from, err := time.ParseInLocation(dayLayout, query.From, u.loc)
+	if err != nil {
+		return response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidParameter, err.Error())
+	}
+	to, err := time.ParseInLocation(dayLayout, query.To, u.loc)
+	if err != nil {
+		return response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidParameter, err.Error())
+	}
+	if to.Before(from) {
+		return response.NewErrorResponse(tokenErr.ErrInvalidRequest, http.StatusBadRequest, nil, response.StatusInvalidParameter, errorUsageRangeMessage)
+	}
+
+	format := "%Y-%m-%d"
+	if query.Granularity == entity.UsagePeriodMonth {
+		format = "%Y-%m"
+	}
+
+	usages, err := u.usageRepository.Aggregate(ctx, query.ClientId, from, to.AddDate(0, 0, 1), format, u.loc)
+	if err != nil {
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	return response.NewSuccessResponse(usages, response.StatOK, getUsageSuccessMessage)
+}
+
+// periods start of the day and of the month of the time, in the service time zone
+func (u *usecase) periods(at time.Time) (day, month time.Time) {
+	at = at.In(u.loc)
+	day = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, u.loc)
+	month = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, u.loc)
+	return
+}
+
+func (u *usecase) decrement(ctx context.Context, clientId, period string, start, at time.Time) {
+	if _, err := u.usageRepository.Increment(ctx, clientId, period, start, -1, at); err != nil {
+		u.logger.WithContext(ctx).Error(err)
+	}
+}
+
+// alert warn once, when the count reaches the alert share of the limit
+func (u *usecase) alert(ctx context.Context, clientId, period string, count, limit int64, percent int) {
+	if limit <= 0 || percent <= 0 {
+		return
+	}
+
+	threshold := (limit*int64(percent) + 99) / 100
+	if count != threshold {
+		return
+	}
+
+	u.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"client_id": clientId,
+		"period":    period,
+		"count":     count,
+		"limit":     limit,
+	}).Warn("channel token quota alert reached")
+}
+
+func isExceeded(count, limit int64) bool {
+	return limit > 0 && count > limit
+}
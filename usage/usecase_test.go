@@ -0,0 +1,67 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	tokenErr "github.com/umerthow/go-oauth/errors"
+	"github.com/umerthow/go-oauth/model"
+)
+
+type memoryUsageRepository struct {
+	counts map[string]int64
+}
+
+func (r *memoryUsageRepository) Increment(ctx context.Context, clientId, period string, start time.Time, delta int64, now time.Time) (int64, error) {
+	key := clientId + "|" + period + "|" + start.Format(time.RFC3339)
+	r.counts[key] += delta
+	return r.counts[key], nil
+}
+
+func (r *memoryUsageRepository) Aggregate(ctx context.Context, clientId string, from, to time.Time, format string, loc *time.Location) ([]model.UsageResponse, error) {
+	return nil, nil
+}
+
+func (r *memoryUsageRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func TestConsumeRejectsExhaustedQuota(t *testing.T) {
+	at := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+	dayKey := "client|" + entity.UsagePeriodDay + "|2024-03-15T00:00:00Z"
+	monthKey := "client|" + entity.UsagePeriodMonth + "|2024-03-01T00:00:00Z"
+
+	tests := []struct {
+		name      string
+		quota     entity.Quota
+		daily     int64
+		monthly   int64
+		wantErr   error
+		wantDaily int64
+	}{
+		{name: "no quota", quota: entity.Quota{}, daily: 100, monthly: 100, wantDaily: 101},
+		{name: "under the daily quota", quota: entity.Quota{Daily: 3}, daily: 2, monthly: 2, wantDaily: 3},
+		{name: "daily quota exhausted", quota: entity.Quota{Daily: 3}, daily: 3, monthly: 3, wantErr: tokenErr.ErrQuotaExceeded, wantDaily: 3},
+		{name: "monthly quota exhausted", quota: entity.Quota{Daily: 10, Monthly: 5}, daily: 0, monthly: 5, wantErr: tokenErr.ErrQuotaExceeded, wantDaily: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryUsageRepository{counts: map[string]int64{dayKey: tt.daily, monthKey: tt.monthly}}
+			u := &usecase{logger: logrus.New(), usageRepository: repo, loc: time.UTC}
+
+			if err := u.Consume(context.Background(), "client", tt.quota, at); err != tt.wantErr {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+			if got := repo.counts[dayKey]; got != tt.wantDaily {
+				t.Errorf("daily count %d, want %d", got, tt.wantDaily)
+			}
+			if tt.wantErr != nil && repo.counts[monthKey] != tt.monthly {
+				t.Errorf("monthly count %d not given back, want %d", repo.counts[monthKey], tt.monthly)
+			}
+		})
+	}
+}
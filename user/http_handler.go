@@ -0,0 +1,423 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/middleware"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+)
+
+const (
+	// LoginPath page the interactive endpoints redirect to without a session
+	LoginPath = "/go-oauth/v1/login"
+	// LoginMFAPath second step of the login for users with TOTP enabled
+	LoginMFAPath = "/go-oauth/v1/login/mfa"
+	// TOTPPath enrolment page of the authenticator app
+	TOTPPath = "/go-oauth/v1/mfa/totp"
+
+	defaultReturnTo = "/go-oauth"
+
+	loginInvalidMessage = "Invalid username or password."
+	loginErrorMessage   = "Something went wrong, please try again."
+	mfaInvalidMessage   = "Invalid code."
+	mfaExpiredMessage   = "Your sign in has expired, please sign in again."
+
+	totpEnabledMessage  = "Two-factor authentication is enabled."
+	totpDisabledMessage = "Two-factor authentication has been turned off."
+	totpInvalidMessage  = "Invalid code, please try again."
+)
+
+type HTTPHandler struct {
+	Logger   *logrus.Logger
+	Validate *validator.Validate
+	Usecase  Usecase
+	Sessions *SessionManager
+}
+
+func NewUserHTTPHandler(logger *logrus.Logger, validate *validator.Validate, router *mux.Router, basicAuth middleware.RouteMiddleware, usecase Usecase, sessions *SessionManager) {
+	handler := &HTTPHandler{
+		Logger:   logger,
+		Validate: validate,
+		Usecase:  usecase,
+		Sessions: sessions,
+	}
+
+	router.HandleFunc("/go-oauth/v1/users", basicAuth.Verify(handler.CreateUser)).Methods(http.MethodPost)
+	router.HandleFunc("/go-oauth/v1/users/{id}", basicAuth.Verify(handler.GetUser)).Methods(http.MethodGet)
+	router.HandleFunc("/go-oauth/v1/users/{id}/status", basicAuth.Verify(handler.UpdateUserStatus)).Methods(http.MethodPut)
+	router.HandleFunc("/go-oauth/v1/users/{id}/totp", basicAuth.Verify(handler.ResetTOTP)).Methods(http.MethodDelete)
+	router.HandleFunc(LoginPath, handler.LoginPage).Methods(http.MethodGet)
+	router.HandleFunc(LoginPath, handler.Login).Methods(http.MethodPost)
+	router.HandleFunc(LoginMFAPath, handler.LoginMFAPage).Methods(http.MethodGet)
+	router.HandleFunc(LoginMFAPath, handler.LoginMFA).Methods(http.MethodPost)
+	router.HandleFunc(TOTPPath, handler.TOTPPage).Methods(http.MethodGet)
+	router.HandleFunc(TOTPPath, handler.TOTP).Methods(http.MethodPost)
+}
+
+// LoginURL login page returning to the given path once signed in
+func LoginURL(returnTo string) string {
+	return LoginPath + "?return_to=" + url.QueryEscape(returnTo)
+}
+
+func (handler *HTTPHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.RequestUser
+	ctx := r.Context()
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp = response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.CreateUser(ctx, payload)
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	resp := handler.Usecase.GetUser(r.Context(), mux.Vars(r)["id"])
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
+	var resp response.Response
+	var payload model.RequestUserStatus
+	ctx := r.Context()
+
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		resp = response.NewErrorResponse(err, http.StatusUnprocessableEntity, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	if err := handler.validateRequestBody(payload); err != nil {
+		resp = response.NewErrorResponse(err, http.StatusBadRequest, nil, response.StatusInvalidPayload, err.Error())
+		response.JSON(w, resp)
+		return
+	}
+
+	resp = handler.Usecase.UpdateUserStatus(ctx, payload, mux.Vars(r)["id"])
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) ResetTOTP(w http.ResponseWriter, r *http.Request) {
+	resp := handler.Usecase.ResetTOTP(r.Context(), mux.Vars(r)["id"])
+	response.JSON(w, resp)
+}
+
+func (handler *HTTPHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page := loginPage{
+		ReturnTo: safeReturnTo(r.URL.Query().Get("return_to")),
+	}
+
+	csrfToken, err := handler.Sessions.LoginCSRF(w, r)
+	if err != nil {
+		handler.Logger.WithContext(ctx).Error(err)
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusInternalServerError, loginTemplate, page)
+		return
+	}
+	page.CSRFToken = csrfToken
+
+	handler.render(w, http.StatusOK, loginTemplate, page)
+}
+
+func (handler *HTTPHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	csrfToken, err := handler.Sessions.LoginCSRF(w, r)
+	if err != nil {
+		handler.Logger.WithContext(ctx).Error(err)
+		handler.render(w, http.StatusInternalServerError, loginTemplate, loginPage{Message: loginErrorMessage})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		handler.render(w, http.StatusBadRequest, loginTemplate, loginPage{CSRFToken: csrfToken, Message: loginErrorMessage})
+		return
+	}
+
+	payload := model.Login{
+		Username: r.PostForm.Get("username"),
+		Password: r.PostForm.Get("password"),
+	}
+	page := loginPage{
+		Username:  payload.Username,
+		ReturnTo:  safeReturnTo(r.PostForm.Get("return_to")),
+		CSRFToken: csrfToken,
+	}
+
+	// a form posted from another site can't know the token of our cookie
+	if !ValidLoginCSRF(r, r.PostForm.Get("csrf_token")) {
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusBadRequest, loginTemplate, page)
+		return
+	}
+
+	user, err := handler.Usecase.Authenticate(ctx, payload)
+	switch err {
+	case nil:
+	case exception.ErrUnauthorized:
+		page.Message = loginInvalidMessage
+		handler.render(w, http.StatusUnauthorized, loginTemplate, page)
+		return
+	default:
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusInternalServerError, loginTemplate, page)
+		return
+	}
+
+	if user.TOTPEnabled {
+		session, err := handler.Sessions.CreatePending(ctx, w, user.ID)
+		if err != nil {
+			handler.Logger.WithContext(ctx).Error(err)
+			page.Message = loginErrorMessage
+			handler.render(w, http.StatusInternalServerError, loginTemplate, page)
+			return
+		}
+
+		handler.render(w, http.StatusOK, mfaTemplate, mfaPage{ReturnTo: page.ReturnTo, CSRFToken: session.CSRFToken})
+		return
+	}
+
+	if _, err := handler.Sessions.Create(ctx, w, user.ID, []string{entity.AMRPassword}); err != nil {
+		handler.Logger.WithContext(ctx).Error(err)
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusInternalServerError, loginTemplate, page)
+		return
+	}
+
+	http.Redirect(w, r, page.ReturnTo, http.StatusFound)
+}
+
+func (handler *HTTPHandler) LoginMFAPage(w http.ResponseWriter, r *http.Request) {
+	returnTo := safeReturnTo(r.URL.Query().Get("return_to"))
+
+	session, err := handler.Sessions.Pending(r)
+	if err != nil {
+		http.Redirect(w, r, LoginURL(returnTo), http.StatusFound)
+		return
+	}
+
+	handler.render(w, http.StatusOK, mfaTemplate, mfaPage{ReturnTo: returnTo, CSRFToken: session.CSRFToken})
+}
+
+// loginCSRF token of a login form rendered in place of another page, empty
+// when it can't be issued and the form is then refused once posted
+func (handler *HTTPHandler) loginCSRF(w http.ResponseWriter, r *http.Request) string {
+	token, err := handler.Sessions.LoginCSRF(w, r)
+	if err != nil {
+		handler.Logger.WithContext(r.Context()).Error(err)
+	}
+	return token
+}
+
+// LoginMFA check the second factor and replace the pending session with a
+// full one recording both factors
+func (handler *HTTPHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		handler.render(w, http.StatusBadRequest, loginTemplate, loginPage{CSRFToken: handler.loginCSRF(w, r), Message: loginErrorMessage})
+		return
+	}
+	returnTo := safeReturnTo(r.PostForm.Get("return_to"))
+
+	pending, err := handler.Sessions.Pending(r)
+	if err != nil {
+		handler.render(w, http.StatusUnauthorized, loginTemplate, loginPage{ReturnTo: returnTo, CSRFToken: handler.loginCSRF(w, r), Message: mfaExpiredMessage})
+		return
+	}
+
+	page := mfaPage{
+		ReturnTo:  returnTo,
+		CSRFToken: pending.CSRFToken,
+	}
+
+	if !ValidCSRF(pending, r.PostForm.Get("csrf_token")) {
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusBadRequest, mfaTemplate, page)
+		return
+	}
+
+	err = handler.Usecase.VerifySecondFactor(ctx, pending.UserID, r.PostForm.Get("code"))
+	switch err {
+	case nil:
+	case exception.ErrUnauthorized:
+		page.Message = mfaInvalidMessage
+		handler.render(w, http.StatusUnauthorized, mfaTemplate, page)
+		return
+	case exception.ErrLocked:
+		if err := handler.Sessions.Destroy(ctx, w, pending); err != nil {
+			handler.Logger.WithContext(ctx).Error(err)
+		}
+		// the sign in starts over, answered like a wrong password
+		handler.render(w, http.StatusUnauthorized, loginTemplate, loginPage{ReturnTo: returnTo, CSRFToken: handler.loginCSRF(w, r), Message: loginInvalidMessage})
+		return
+	default:
+		handler.Logger.WithContext(ctx).Error(err)
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusInternalServerError, mfaTemplate, page)
+		return
+	}
+
+	if err := handler.Sessions.Destroy(ctx, w, pending); err != nil {
+		handler.Logger.WithContext(ctx).Error(err)
+	}
+
+	amr := []string{entity.AMRPassword, entity.AMROTP, entity.AMRMFA}
+	if _, err := handler.Sessions.Create(ctx, w, pending.UserID, amr); err != nil {
+		handler.Logger.WithContext(ctx).Error(err)
+		page.Message = loginErrorMessage
+		handler.render(w, http.StatusInternalServerError, mfaTemplate, page)
+		return
+	}
+
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// TOTPPage start the enrolment of an authenticator app for the signed in user
+func (handler *HTTPHandler) TOTPPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		http.Redirect(w, r, LoginURL(r.URL.RequestURI()), http.StatusFound)
+		return
+	}
+
+	page := totpPage{CSRFToken: session.CSRFToken}
+
+	enrolment, err := handler.Usecase.EnrollTOTP(ctx, session.UserID)
+	switch err {
+	case nil:
+		page.Secret = enrolment.Secret
+		page.ProvisioningURI = enrolment.ProvisioningURI
+	case exception.ErrConflict:
+		page.Enabled = true
+		page.Message = totpEnabledMessage
+	default:
+		handler.Logger.WithContext(ctx).Error(err)
+		page.Message, page.IsError = loginErrorMessage, true
+		handler.render(w, http.StatusInternalServerError, totpTemplate, page)
+		return
+	}
+
+	handler.render(w, http.StatusOK, totpTemplate, page)
+}
+
+// TOTP confirm the enrolment, showing the recovery codes once, or turn TOTP off
+func (handler *HTTPHandler) TOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	session, err := handler.Sessions.Current(r)
+	if err != nil {
+		http.Redirect(w, r, LoginURL(r.URL.Path), http.StatusFound)
+		return
+	}
+
+	page := totpPage{CSRFToken: session.CSRFToken}
+
+	if err := r.ParseForm(); err != nil || !ValidCSRF(session, r.PostForm.Get("csrf_token")) {
+		page.Message, page.IsError = loginErrorMessage, true
+		handler.render(w, http.StatusBadRequest, totpTemplate, page)
+		return
+	}
+	code := r.PostForm.Get("code")
+
+	if r.PostForm.Get("action") == "disable" {
+		page.Enabled = true
+		switch err := handler.Usecase.DisableTOTP(ctx, session.UserID, code); err {
+		case nil:
+			page.Enabled = false
+			page.Message = totpDisabledMessage
+			handler.render(w, http.StatusOK, totpTemplate, page)
+		case exception.ErrUnauthorized, exception.ErrLocked:
+			page.Message, page.IsError = totpInvalidMessage, true
+			handler.render(w, http.StatusUnauthorized, totpTemplate, page)
+		default:
+			handler.Logger.WithContext(ctx).Error(err)
+			page.Message, page.IsError = loginErrorMessage, true
+			handler.render(w, http.StatusInternalServerError, totpTemplate, page)
+		}
+		return
+	}
+
+	recoveryCodes, err := handler.Usecase.ConfirmTOTP(ctx, session.UserID, code)
+	switch err {
+	case nil:
+		page.Enabled = true
+		page.RecoveryCodes = recoveryCodes
+		page.Message = totpEnabledMessage
+		handler.render(w, http.StatusOK, totpTemplate, page)
+	case exception.ErrUnauthorized, exception.ErrBadRequest:
+		// the pending secret is kept, show it again to retry
+		enrolment, errEnroll := handler.Usecase.EnrollTOTP(ctx, session.UserID)
+		if errEnroll != nil {
+			handler.Logger.WithContext(ctx).Error(errEnroll)
+		}
+		page.Secret = enrolment.Secret
+		page.ProvisioningURI = enrolment.ProvisioningURI
+		page.Message, page.IsError = totpInvalidMessage, true
+		handler.render(w, http.StatusUnauthorized, totpTemplate, page)
+	case exception.ErrConflict:
+		page.Enabled = true
+		page.Message = totpEnabledMessage
+		handler.render(w, http.StatusConflict, totpTemplate, page)
+	default:
+		handler.Logger.WithContext(ctx).Error(err)
+		page.Message, page.IsError = loginErrorMessage, true
+		handler.render(w, http.StatusInternalServerError, totpTemplate, page)
+	}
+}
+
+func (handler *HTTPHandler) render(w http.ResponseWriter, statusCode int, tmpl *template.Template, page interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := tmpl.Execute(w, page); err != nil {
+		handler.Logger.Error(err)
+	}
+}
+
+// safeReturnTo only allow returning to a path of this service, never another host
+func safeReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, defaultReturnTo+"/") || strings.HasPrefix(returnTo, "//") || strings.Contains(returnTo, "\\") {
+		return defaultReturnTo
+	}
+
+	return returnTo
+}
+
+func (handler *HTTPHandler) validateRequestBody(body interface{}) (err error) {
+	err = handler.Validate.Struct(body)
+	if err == nil {
+		return
+	}
+
+	errorFields := err.(validator.ValidationErrors)
+	errorField := errorFields[0]
+	err = fmt.Errorf("invalid '%s' with value '%v'", errorField.Field(), errorField.Value())
+
+	return
+}
@@ -0,0 +1,14 @@
+package user
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type UsecaseUserProperty struct {
+	ServiceName     string
+	Logger          *logrus.Logger
+	Location        *time.Location
+	UsersRepository UsersRepository
+}
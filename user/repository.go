@@ -0,0 +1,167 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type UsersRepository interface {
+	InsertOne(ctx context.Context, entryData entity.User) (err error)
+	FindByID(ctx context.Context, id string) (user entity.User, err error)
+	FindByUsername(ctx context.Context, username string) (user entity.User, err error)
+	UpdateOne(ctx context.Context, id string, fields bson.M) (err error)
+	// UseTOTPStep record the time step of an accepted code, exception.ErrNotFound
+	// when that step or a later one was already used
+	UseTOTPStep(ctx context.Context, id string, step int64) (err error)
+	// UseRecoveryCode remove the hashed recovery code, exception.ErrNotFound when
+	// it is unknown or was already used
+	UseRecoveryCode(ctx context.Context, id string, hash string) (err error)
+	// RecordFailedLogin count a failed attempt in a single update, concurrent
+	// attempts can't overwrite each other's count. Once max attempts are
+	// reached the count restarts and the account is locked until lockUntil.
+	RecordFailedLogin(ctx context.Context, id string, max int, lockUntil time.Time) (err error)
+	// EnsureIndexes create the index keeping usernames unique
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type userRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewUserRepository(logger *logrus.Logger, db mongodb.Database) UsersRepository {
+	col := db.Collection("oauth_user")
+	return &userRepository{logger, col}
+}
+
+// InsertOne return exception.ErrConflict when the username is taken, a concurrent creation included
+func (r *userRepository) InsertOne(ctx context.Context, entryData entity.User) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = exception.ErrConflict
+			return
+		}
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *userRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.UniqueIndex("username")); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (user entity.User, err error) {
+	return r.findOne(ctx, bson.M{"id": id})
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (user entity.User, err error) {
+	return r.findOne(ctx, bson.M{"username": username})
+}
+
+func (r *userRepository) findOne(ctx context.Context, filter bson.M) (user entity.User, err error) {
+	if err = r.col.FindOne(ctx, filter).Decode(&user); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *userRepository) UpdateOne(ctx context.Context, id string, fields bson.M) (err error) {
+	resp, err := r.col.UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": fields})
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.MatchedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, id string, step int64) (err error) {
+	filter := bson.M{
+		"id":             id,
+		"totp_last_step": bson.M{"$lt": step},
+	}
+
+	return r.updateMatched(ctx, filter, bson.M{"$set": bson.M{"totp_last_step": step}})
+}
+
+func (r *userRepository) UseRecoveryCode(ctx context.Context, id string, hash string) (err error) {
+	filter := bson.M{
+		"id":             id,
+		"recovery_codes": hash,
+	}
+
+	return r.updateMatched(ctx, filter, bson.M{"$pull": bson.M{"recovery_codes": hash}})
+}
+
+func (r *userRepository) RecordFailedLogin(ctx context.Context, id string, max int, lockUntil time.Time) (err error) {
+	var user entity.User
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$inc": bson.M{"failed_login_attempts": 1}}
+
+	if err = r.col.FindOneAndUpdate(ctx, bson.M{"id": id}, update, opts).Decode(&user); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	if user.FailedLoginAttempts < max {
+		return
+	}
+
+	// the filter keeps a reset made in between from locking the account
+	filter := bson.M{
+		"id":                    id,
+		"failed_login_attempts": bson.M{"$gte": max},
+	}
+	fields := bson.M{
+		"failed_login_attempts": 0,
+		"locked_until":          lockUntil,
+	}
+	if _, err = r.col.UpdateOne(ctx, filter, bson.M{"$set": fields}); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *userRepository) updateMatched(ctx context.Context, filter, update bson.M) (err error) {
+	resp, err := r.col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+		return
+	}
+
+	if resp.MatchedCount == 0 {
+		err = exception.ErrNotFound
+	}
+	return
+}
@@ -0,0 +1,213 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+)
+
+const (
+	// SessionCookieName cookie carrying the session id
+	SessionCookieName = "go_oauth_session"
+	// LoginCSRFCookieName cookie carrying the token the login form echoes, there is no session to keep it in yet
+	LoginCSRFCookieName = "go_oauth_login_csrf"
+	// SessionLifetime maximum age of a browser session
+	SessionLifetime = time.Hour * 8
+	// MFAChallengeLifetime time left to enter the second factor after the password
+	MFAChallengeLifetime = time.Minute * 5
+
+	sessionCookiePath = "/go-oauth"
+)
+
+// SessionManager bind browser requests to the session of the logged in user
+type SessionManager struct {
+	repository   SessionsRepository
+	loc          *time.Location
+	secureCookie bool
+}
+
+// NewSessionManager is a constructor. secureCookie should be true whenever
+// the service is reached over https.
+func NewSessionManager(repository SessionsRepository, loc *time.Location, secureCookie bool) *SessionManager {
+	return &SessionManager{
+		repository:   repository,
+		loc:          loc,
+		secureCookie: secureCookie,
+	}
+}
+
+// Current return the session of the request, exception.ErrNotFound when
+// there is none, it has expired or it still waits for the second factor.
+func (m *SessionManager) Current(r *http.Request) (session entity.Session, err error) {
+	session, err = m.find(r)
+	if err != nil {
+		return
+	}
+
+	if session.MFAPending {
+		return entity.Session{}, exception.ErrNotFound
+	}
+
+	return
+}
+
+// Pending return the session of the request waiting for the second factor
+func (m *SessionManager) Pending(r *http.Request) (session entity.Session, err error) {
+	session, err = m.find(r)
+	if err != nil {
+		return
+	}
+
+	if !session.MFAPending {
+		return entity.Session{}, exception.ErrNotFound
+	}
+
+	return
+}
+
+func (m *SessionManager) find(r *http.Request) (session entity.Session, err error) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return session, exception.ErrNotFound
+	}
+
+	session, err = m.repository.FindByID(r.Context(), cookie.Value)
+	if err != nil {
+		return
+	}
+
+	if session.IsExpired(time.Now().In(m.loc)) {
+		return entity.Session{}, exception.ErrNotFound
+	}
+
+	return
+}
+
+// Create start a new session for the user authenticated with the amr methods and set its cookie
+func (m *SessionManager) Create(ctx context.Context, w http.ResponseWriter, userID string, amr []string) (session entity.Session, err error) {
+	return m.create(ctx, w, userID, amr, false, SessionLifetime)
+}
+
+// CreatePending start a short session for a user who passed the password
+// check and still has to enter the second factor
+func (m *SessionManager) CreatePending(ctx context.Context, w http.ResponseWriter, userID string) (session entity.Session, err error) {
+	return m.create(ctx, w, userID, []string{entity.AMRPassword}, true, MFAChallengeLifetime)
+}
+
+func (m *SessionManager) create(ctx context.Context, w http.ResponseWriter, userID string, amr []string, mfaPending bool, lifetime time.Duration) (session entity.Session, err error) {
+	now := time.Now().In(m.loc)
+
+	id, err := randomToken(32)
+	if err != nil {
+		return
+	}
+
+	csrfToken, err := randomToken(32)
+	if err != nil {
+		return
+	}
+
+	session = entity.Session{
+		ID:         id,
+		UserID:     userID,
+		AuthTime:   now,
+		AMR:        amr,
+		MFAPending: mfaPending,
+		CSRFToken:  csrfToken,
+		ExpiresAt:  now.Add(lifetime),
+		CreatedAt:  now,
+	}
+
+	if err = m.repository.InsertOne(ctx, session); err != nil {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    session.ID,
+		Path:     sessionCookiePath,
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   m.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return
+}
+
+// Destroy delete the session and clear its cookie
+func (m *SessionManager) Destroy(ctx context.Context, w http.ResponseWriter, session entity.Session) (err error) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     sessionCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   m.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return m.repository.DeleteOne(ctx, session.ID)
+}
+
+// AddClient remember that the channel took part in the session so it can be
+// told when the session ends
+func (m *SessionManager) AddClient(ctx context.Context, session entity.Session, clientId string) (err error) {
+	return m.repository.AddClient(ctx, session.ID, clientId)
+}
+
+// ValidCSRF report whether the submitted form token belongs to the session
+func ValidCSRF(session entity.Session, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(session.CSRFToken), []byte(token)) == 1
+}
+
+// LoginCSRF token of the login form, kept in a cookie the form must match
+// (double submit). The token of the request is reused so several open tabs
+// keep working.
+func (m *SessionManager) LoginCSRF(w http.ResponseWriter, r *http.Request) (string, error) {
+	if cookie, err := r.Cookie(LoginCSRFCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     LoginCSRFCookieName,
+		Value:    token,
+		Path:     sessionCookiePath,
+		Expires:  time.Now().In(m.loc).Add(SessionLifetime),
+		HttpOnly: true,
+		Secure:   m.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return token, nil
+}
+
+// ValidLoginCSRF report whether the submitted login form echoes the token of its cookie
+func ValidLoginCSRF(r *http.Request, token string) bool {
+	cookie, err := r.Cookie(LoginCSRFCookieName)
+	if err != nil || cookie.Value == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(token)) == 1
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,77 @@
+package user
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionsRepository interface {
+	InsertOne(ctx context.Context, entryData entity.Session) (err error)
+	FindByID(ctx context.Context, id string) (session entity.Session, err error)
+	DeleteOne(ctx context.Context, id string) (err error)
+	AddClient(ctx context.Context, id, clientId string) (err error)
+	// EnsureIndexes create the index removing the sessions once they expire
+	EnsureIndexes(ctx context.Context) (err error)
+}
+
+type sessionRepository struct {
+	logger *logrus.Logger
+	col    mongodb.Collection
+}
+
+func NewSessionRepository(logger *logrus.Logger, db mongodb.Database) SessionsRepository {
+	col := db.Collection("oauth_session")
+	return &sessionRepository{logger, col}
+}
+
+func (r *sessionRepository) InsertOne(ctx context.Context, entryData entity.Session) (err error) {
+	if _, err = r.col.InsertOne(ctx, entryData); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (session entity.Session, err error) {
+	if err = r.col.FindOne(ctx, bson.M{"id": id}).Decode(&session); err != nil {
+		if err != mongo.ErrNoDocuments {
+			r.logger.WithContext(ctx).Error(err)
+			err = exception.ErrInternalServer
+			return
+		}
+		err = exception.ErrNotFound
+		return
+	}
+
+	return
+}
+
+func (r *sessionRepository) DeleteOne(ctx context.Context, id string) (err error) {
+	if _, err = r.col.DeleteOne(ctx, bson.M{"id": id}); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *sessionRepository) AddClient(ctx context.Context, id, clientId string) (err error) {
+	if _, err = r.col.UpdateOne(ctx, bson.M{"id": id}, bson.M{"$addToSet": bson.M{"client_ids": clientId}}); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
+
+func (r *sessionRepository) EnsureIndexes(ctx context.Context) (err error) {
+	if err = mongodb.EnsureIndexes(ctx, r.col, mongodb.TTLIndex("expires_at", 0)); err != nil {
+		r.logger.WithContext(ctx).Error(err)
+		err = exception.ErrInternalServer
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package user
+
+import "html/template"
+
+type loginPage struct {
+	Username  string
+	ReturnTo  string
+	CSRFToken string
+	Message   string
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Sign in</title>
+</head>
+<body>
+	<h1>Sign in</h1>
+	{{if .Message}}<p style="color:#c00">{{.Message}}</p>{{end}}
+	<form method="POST" action="/go-oauth/v1/login">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<input type="hidden" name="return_to" value="{{.ReturnTo}}">
+		<label for="username">Username</label>
+		<input id="username" name="username" value="{{.Username}}" autocomplete="username" autofocus>
+		<label for="password">Password</label>
+		<input id="password" name="password" type="password" autocomplete="current-password">
+		<button type="submit">Sign in</button>
+	</form>
+</body>
+</html>
+`))
+
+type mfaPage struct {
+	ReturnTo  string
+	CSRFToken string
+	Message   string
+}
+
+var mfaTemplate = template.Must(template.New("mfa").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Two-factor authentication</title>
+</head>
+<body>
+	<h1>Two-factor authentication</h1>
+	{{if .Message}}<p style="color:#c00">{{.Message}}</p>{{end}}
+	<form method="POST" action="/go-oauth/v1/login/mfa">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<input type="hidden" name="return_to" value="{{.ReturnTo}}">
+		<label for="code">Enter the code from your authenticator app or a recovery code</label>
+		<input id="code" name="code" autocomplete="one-time-code" autofocus>
+		<button type="submit">Verify</button>
+	</form>
+</body>
+</html>
+`))
+
+type totpPage struct {
+	Enabled         bool
+	Secret          string
+	ProvisioningURI string
+	RecoveryCodes   []string
+	CSRFToken       string
+	Message         string
+	IsError         bool
+}
+
+var totpTemplate = template.Must(template.New("totp").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Authenticator app</title>
+</head>
+<body>
+	<h1>Authenticator app</h1>
+	{{if .Message}}<p{{if .IsError}} style="color:#c00"{{end}}>{{.Message}}</p>{{end}}
+	{{if .RecoveryCodes}}
+	<p>Store these recovery codes somewhere safe, each one can be used once if you lose your device. They won't be shown again.</p>
+	<ul>
+		{{range .RecoveryCodes}}<li><code>{{.}}</code></li>{{end}}
+	</ul>
+	{{else if .Enabled}}
+	<form method="POST">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<label for="code">Enter a code to turn two-factor authentication off</label>
+		<input id="code" name="code" autocomplete="one-time-code">
+		<button type="submit" name="action" value="disable">Disable</button>
+	</form>
+	{{else}}
+	<p>Scan this URI as a QR code with your authenticator app, or enter the secret manually.</p>
+	<p><code>{{.ProvisioningURI}}</code></p>
+	<p>Secret: <code>{{.Secret}}</code></p>
+	<form method="POST">
+		<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+		<label for="code">Enter the code shown by the app</label>
+		<input id="code" name="code" autocomplete="one-time-code" autofocus>
+		<button type="submit" name="action" value="enable">Enable</button>
+	</form>
+	{{end}}
+</body>
+</html>
+`))
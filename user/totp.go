@@ -0,0 +1,121 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPPeriod seconds a code stays valid (RFC 6238 section 4)
+	TOTPPeriod = 30
+	// TOTPDigits length of a code
+	TOTPDigits = 6
+	// RecoveryCodeCount recovery codes issued when TOTP is enabled
+	RecoveryCodeCount = 10
+
+	// totpSkew steps accepted on each side of the current one, for clock drift
+	totpSkew = 1
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateTOTPSecret 160 bits secret, the size RFC 4226 recommends for HMAC-SHA1
+func generateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base32NoPadding.EncodeToString(buf), nil
+}
+
+// totpStep time step counter of the given time
+func totpStep(now time.Time) int64 {
+	return now.Unix() / TOTPPeriod
+}
+
+// totpCode HOTP value of the step (RFC 4226 section 5.3)
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%1000000)
+}
+
+// validateTOTP return the step the code belongs to. Steps up to lastStep were
+// already used and are rejected so a code can't be replayed.
+func validateTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	current := totpStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// provisioningURI otpauth uri authenticator apps read from a QR code
+func provisioningURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(TOTPDigits))
+	params.Set("period", fmt.Sprint(TOTPPeriod))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// generateRecoveryCodes plain codes to show the user once and the hashes to store
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err = rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(base32NoPadding.EncodeToString(buf))
+		code = code[:4] + "-" + code[4:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return
+}
+
+// hashRecoveryCode codes are random so a plain SHA-256 is enough to store them
+func hashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
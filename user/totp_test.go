@@ -0,0 +1,61 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret shared secret of the SHA1 test vectors of RFC 6238 appendix B
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// the appendix lists 8 digit codes, a 6 digit code is their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		if got := totpCode(rfc6238Secret, totpStep(time.Unix(tt.unix, 0))); got != tt.want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := base32NoPadding.EncodeToString(rfc6238Secret)
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "current step", code: "050471", wantStep: step, wantOk: true},
+		{name: "previous step within the skew", code: totpCode(rfc6238Secret, step-1), wantStep: step - 1, wantOk: true},
+		{name: "next step within the skew", code: totpCode(rfc6238Secret, step+1), wantStep: step + 1, wantOk: true},
+		{name: "step beyond the skew", code: totpCode(rfc6238Secret, step-2)},
+		{name: "replayed step", code: "050471", lastStep: step},
+		{name: "wrong code", code: "000000"},
+		{name: "wrong length", code: "50471"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOk := validateTOTP(secret, tt.code, now, tt.lastStep)
+			if gotOk != tt.wantOk || gotStep != tt.wantStep {
+				t.Errorf("validateTOTP() = (%d, %v), want (%d, %v)", gotStep, gotOk, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
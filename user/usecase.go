@@ -0,0 +1,411 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"github.com/umerthow/go-oauth/response"
+	"github.com/umerthow/go-oauth/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	createUserSuccessMessage = "Create User Successfully"
+	getUserSuccessMessage    = "Get User Successfully"
+	updateUserSuccessMessage = "Update User Successfully"
+	resetTOTPSuccessMessage  = "Reset TOTP Successfully"
+	errorCreateUserMessage   = "Create User Failed!"
+	errorUserExistMessage    = "Username Already Exist"
+	errorUserNotFoundMessage = "User Not Found"
+
+	// MaxFailedLoginAttempts consecutive wrong passwords before the account is locked
+	MaxFailedLoginAttempts = 5
+	// LoginLockDuration how long an account stays locked after too many failures
+	LoginLockDuration = time.Minute * 15
+)
+
+// dummyPasswordHash hash compared when there is no account to check the password against
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("go-oauth dummy password"), bcrypt.DefaultCost)
+
+type Usecase interface {
+	CreateUser(ctx context.Context, payload model.RequestUser) response.Response
+	GetUser(ctx context.Context, userID string) response.Response
+	UpdateUserStatus(ctx context.Context, payload model.RequestUserStatus, userID string) response.Response
+	Authenticate(ctx context.Context, payload model.Login) (user entity.User, err error)
+	FindUserInfo(ctx context.Context, subject string) (userInfo entity.UserInfo, err error)
+	IsUserActive(ctx context.Context, subject string) (active bool, err error)
+	EnrollTOTP(ctx context.Context, userID string) (enrolment model.TOTPEnrolment, err error)
+	ConfirmTOTP(ctx context.Context, userID, code string) (recoveryCodes []string, err error)
+	DisableTOTP(ctx context.Context, userID, code string) (err error)
+	VerifySecondFactor(ctx context.Context, userID, code string) (err error)
+	ResetTOTP(ctx context.Context, userID string) response.Response
+}
+
+type usecase struct {
+	serviceName    string
+	logger         *logrus.Logger
+	userRepository UsersRepository
+	loc            *time.Location
+}
+
+func NewUserUsecase(property UsecaseUserProperty) *usecase {
+	return &usecase{
+		serviceName:    property.ServiceName,
+		logger:         property.Logger,
+		userRepository: property.UsersRepository,
+		loc:            property.Location,
+	}
+}
+
+func (u *usecase) CreateUser(ctx context.Context, payload model.RequestUser) response.Response {
+	ctx, span := tracing.Start(ctx, "user.CreateUser")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	if _, err := u.userRepository.FindByUsername(ctx, payload.Username); err != exception.ErrNotFound {
+		if err == nil {
+			return response.NewErrorResponse(exception.ErrConflict, http.StatusConflict, nil, response.StatAlreadyExist, errorUserExistMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorCreateUserMessage)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	if err != nil {
+		u.logger.WithContext(ctx).Error(err)
+		return response.NewErrorResponse(exception.ErrInternalServer, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorCreateUserMessage)
+	}
+
+	user := entity.User{
+		ID:            uuid.NewString(),
+		Username:      payload.Username,
+		Email:         payload.Email,
+		EmailVerified: payload.EmailVerified,
+		PasswordHash:  string(hash),
+		Name:          payload.Name,
+		GivenName:     payload.GivenName,
+		FamilyName:    payload.FamilyName,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := u.userRepository.InsertOne(ctx, user); err != nil {
+		if err == exception.ErrConflict {
+			return response.NewErrorResponse(err, http.StatusConflict, nil, response.StatAlreadyExist, errorUserExistMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, errorCreateUserMessage)
+	}
+
+	return response.NewSuccessResponse(user, response.StatCreated, createUserSuccessMessage)
+}
+
+func (u *usecase) GetUser(ctx context.Context, userID string) response.Response {
+	ctx, span := tracing.Start(ctx, "user.GetUser")
+	defer span.End()
+
+	user, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(err, http.StatusNotFound, nil, response.StatNotFound, errorUserNotFoundMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	return response.NewSuccessResponse(user, response.StatOK, getUserSuccessMessage)
+}
+
+// UpdateUserStatus enable/disable or lock/unlock the account, unlocking also
+// clears the lockout left by failed logins.
+func (u *usecase) UpdateUserStatus(ctx context.Context, payload model.RequestUserStatus, userID string) response.Response {
+	ctx, span := tracing.Start(ctx, "user.UpdateUserStatus")
+	defer span.End()
+
+	fields := bson.M{
+		"updated_at": time.Now().In(u.loc),
+	}
+
+	if payload.IsActive != nil {
+		fields["is_active"] = *payload.IsActive
+	}
+
+	if payload.IsLocked != nil {
+		fields["is_locked"] = *payload.IsLocked
+		if !*payload.IsLocked {
+			fields["failed_login_attempts"] = 0
+			fields["locked_until"] = time.Time{}
+		}
+	}
+
+	if err := u.userRepository.UpdateOne(ctx, userID, fields); err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(err, http.StatusNotFound, nil, response.StatNotFound, errorUserNotFoundMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	return response.NewSuccessResponse("", response.StatOK, updateUserSuccessMessage)
+}
+
+// Authenticate check the password of an active account. Every failure counts
+// towards a temporary lock, exception.ErrUnauthorized hides whether the
+// username exists or is locked. The attempts are cleared once every factor of the account
+// is checked. Unknown and locked accounts are compared against a dummy
+// hash, every attempt costs one bcrypt comparison and timing reveals nothing.
+func (u *usecase) Authenticate(ctx context.Context, payload model.Login) (user entity.User, err error) {
+	ctx, span := tracing.Start(ctx, "user.Authenticate")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	user, err = u.userRepository.FindByUsername(ctx, payload.Username)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			compareDummyPassword(payload.Password)
+			err = exception.ErrUnauthorized
+		}
+		return
+	}
+
+	// refused like an unknown username, a locked account mustn't tell an
+	// attacker the username exists
+	if !user.CanLogin(now) {
+		compareDummyPassword(payload.Password)
+		u.logger.WithContext(ctx).WithField("user_id", user.ID).Warn("login refused, the account is locked or disabled")
+		return entity.User{}, exception.ErrUnauthorized
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(payload.Password)); err != nil {
+		u.recordFailedLogin(ctx, user, now)
+		return entity.User{}, exception.ErrUnauthorized
+	}
+
+	// with a second factor the attempts are only cleared by VerifySecondFactor,
+	// a known password mustn't give unlimited guesses at the code
+	if !user.TOTPEnabled {
+		if err = u.resetFailedLogins(ctx, user); err != nil {
+			return entity.User{}, err
+		}
+	}
+
+	return user, nil
+}
+
+// EnrollTOTP start the enrolment, a pending secret is reused so the page can
+// be reloaded. TOTP is only enabled once ConfirmTOTP receives a code from it.
+func (u *usecase) EnrollTOTP(ctx context.Context, userID string) (enrolment model.TOTPEnrolment, err error) {
+	ctx, span := tracing.Start(ctx, "user.EnrollTOTP")
+	defer span.End()
+
+	user, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	if user.TOTPEnabled {
+		return enrolment, exception.ErrConflict
+	}
+
+	secret := user.TOTPSecret
+	if secret == "" {
+		if secret, err = generateTOTPSecret(); err != nil {
+			return
+		}
+
+		fields := bson.M{
+			"totp_secret":    secret,
+			"totp_last_step": 0,
+			"updated_at":     time.Now().In(u.loc),
+		}
+		if err = u.userRepository.UpdateOne(ctx, user.ID, fields); err != nil {
+			return
+		}
+	}
+
+	enrolment = model.TOTPEnrolment{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI(u.serviceName, user.Username, secret),
+	}
+
+	return
+}
+
+// ConfirmTOTP enable TOTP when the code matches the pending secret and return
+// the recovery codes, they are only stored hashed and can't be shown again
+func (u *usecase) ConfirmTOTP(ctx context.Context, userID, code string) (recoveryCodes []string, err error) {
+	ctx, span := tracing.Start(ctx, "user.ConfirmTOTP")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	user, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	if user.TOTPEnabled {
+		return nil, exception.ErrConflict
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, exception.ErrBadRequest
+	}
+
+	step, ok := validateTOTP(user.TOTPSecret, code, now, user.TOTPLastStep)
+	if !ok {
+		return nil, exception.ErrUnauthorized
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return
+	}
+
+	fields := bson.M{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+		"recovery_codes": hashes,
+		"updated_at":     now,
+	}
+	if err = u.userRepository.UpdateOne(ctx, user.ID, fields); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// DisableTOTP turn the second factor off, it takes a valid code to do so
+func (u *usecase) DisableTOTP(ctx context.Context, userID, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "user.DisableTOTP")
+	defer span.End()
+
+	if err = u.VerifySecondFactor(ctx, userID, code); err != nil {
+		return
+	}
+
+	return u.userRepository.UpdateOne(ctx, userID, totpResetFields(time.Now().In(u.loc)))
+}
+
+// VerifySecondFactor accept a TOTP code or an unused recovery code. Failures
+// count towards the same lock as wrong passwords.
+func (u *usecase) VerifySecondFactor(ctx context.Context, userID, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "user.VerifySecondFactor")
+	defer span.End()
+
+	now := time.Now().In(u.loc)
+
+	user, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			err = exception.ErrUnauthorized
+		}
+		return
+	}
+
+	if !user.CanLogin(now) {
+		return exception.ErrLocked
+	}
+
+	if !user.TOTPEnabled {
+		return exception.ErrBadRequest
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := validateTOTP(user.TOTPSecret, code, now, user.TOTPLastStep); ok {
+		err = u.userRepository.UseTOTPStep(ctx, user.ID, step)
+	} else {
+		err = u.userRepository.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+	}
+
+	if err != nil {
+		if err != exception.ErrNotFound {
+			return
+		}
+		u.recordFailedLogin(ctx, user, now)
+		return exception.ErrUnauthorized
+	}
+
+	return u.resetFailedLogins(ctx, user)
+}
+
+// ResetTOTP remove the second factor of a user who lost both the device and the recovery codes
+func (u *usecase) ResetTOTP(ctx context.Context, userID string) response.Response {
+	ctx, span := tracing.Start(ctx, "user.ResetTOTP")
+	defer span.End()
+
+	if err := u.userRepository.UpdateOne(ctx, userID, totpResetFields(time.Now().In(u.loc))); err != nil {
+		if err == exception.ErrNotFound {
+			return response.NewErrorResponse(err, http.StatusNotFound, nil, response.StatNotFound, errorUserNotFoundMessage)
+		}
+		return response.NewErrorResponse(err, http.StatusInternalServerError, nil, response.StatUnexpectedError, err.Error())
+	}
+
+	return response.NewSuccessResponse("", response.StatOK, resetTOTPSuccessMessage)
+}
+
+// recordFailedLogin count a failed attempt and lock the account once there are too many
+func (u *usecase) recordFailedLogin(ctx context.Context, user entity.User, now time.Time) {
+	if err := u.userRepository.RecordFailedLogin(ctx, user.ID, MaxFailedLoginAttempts, now.Add(LoginLockDuration)); err != nil {
+		u.logger.WithContext(ctx).Error(err)
+	}
+}
+
+func (u *usecase) resetFailedLogins(ctx context.Context, user entity.User) error {
+	if user.FailedLoginAttempts == 0 {
+		return nil
+	}
+
+	return u.userRepository.UpdateOne(ctx, user.ID, bson.M{"failed_login_attempts": 0})
+}
+
+// compareDummyPassword spend the time of a real password check
+func compareDummyPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
+func totpResetFields(now time.Time) bson.M {
+	return bson.M{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+		"recovery_codes": []string{},
+		"updated_at":     now,
+	}
+}
+
+// FindUserInfo implement oauth.UserInfoFinder
+func (u *usecase) FindUserInfo(ctx context.Context, subject string) (userInfo entity.UserInfo, err error) {
+	ctx, span := tracing.Start(ctx, "user.FindUserInfo")
+	defer span.End()
+
+	user, err := u.userRepository.FindByID(ctx, subject)
+	if err != nil {
+		return
+	}
+
+	return user.UserInfo(), nil
+}
+
+// IsUserActive implement oauth.UserStatusChecker, an unknown user isn't active
+func (u *usecase) IsUserActive(ctx context.Context, subject string) (active bool, err error) {
+	ctx, span := tracing.Start(ctx, "user.IsUserActive")
+	defer span.End()
+
+	user, err := u.userRepository.FindByID(ctx, subject)
+	if err != nil {
+		if err == exception.ErrNotFound {
+			return false, nil
+		}
+		return
+	}
+
+	return user.CanLogin(time.Now().In(u.loc)), nil
+}
@@ -0,0 +1,67 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/umerthow/go-oauth/entity"
+	"github.com/umerthow/go-oauth/exception"
+	"github.com/umerthow/go-oauth/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// stubUsersRepository UsersRepository finding the users of the map by username
+type stubUsersRepository struct {
+	UsersRepository
+	users map[string]entity.User
+}
+
+func (r stubUsersRepository) FindByUsername(ctx context.Context, username string) (entity.User, error) {
+	user, ok := r.users[username]
+	if !ok {
+		return entity.User{}, exception.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r stubUsersRepository) ResetFailedLogins(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestAuthenticateHidesLockedAccounts(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := NewUserUsecase(UsecaseUserProperty{
+		Logger:   logrus.New(),
+		Location: time.UTC,
+		UsersRepository: stubUsersRepository{users: map[string]entity.User{
+			"active":   {ID: "1", Username: "active", PasswordHash: string(hash), IsActive: true},
+			"locked":   {ID: "2", Username: "locked", PasswordHash: string(hash), IsActive: true, LockedUntil: time.Now().Add(time.Hour)},
+			"disabled": {ID: "3", Username: "disabled", PasswordHash: string(hash)},
+		}},
+	})
+
+	tests := []struct {
+		username string
+		want     error
+	}{
+		{username: "active"},
+		// the right password of a locked or disabled account is refused like an unknown username
+		{username: "locked", want: exception.ErrUnauthorized},
+		{username: "disabled", want: exception.ErrUnauthorized},
+		{username: "unknown", want: exception.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			if _, err := u.Authenticate(context.Background(), model.Login{Username: tt.username, Password: "password"}); err != tt.want {
+				t.Errorf("Authenticate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}